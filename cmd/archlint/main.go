@@ -0,0 +1,38 @@
+// Package main 分层规则检查命令
+//
+// 用法: go run ./cmd/archlint [包路径...]，默认检查 ./...
+// 输出格式与 go vet 相同（文件:行:列: 信息），存在违规时退出码为1，包无法加载时为2
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ruleback/internal/archlint"
+)
+
+func main() {
+	patterns := os.Args[1:]
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	diags, err := archlint.Run(patterns...)
+
+	wd, _ := os.Getwd()
+	for _, d := range diags {
+		if rel, relErr := filepath.Rel(wd, d.Pos.Filename); relErr == nil {
+			d.Pos.Filename = rel
+		}
+		fmt.Fprintln(os.Stderr, d)
+	}
+
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "archlint: %v\n", err)
+		os.Exit(2)
+	case len(diags) > 0:
+		os.Exit(1)
+	}
+}
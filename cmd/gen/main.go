@@ -0,0 +1,118 @@
+// Package main 代码生成工具
+//
+//	go run ./cmd/gen module order --comment 订单 \
+//	    --field order_no:string:required,unique,size=64,filter,comment=订单号 \
+//	    --field amount:int64:required,comment=金额（分）
+//	go run ./cmd/gen module --spec order.yaml
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"ruleback/internal/generator"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Printf("执行失败: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCommand 创建根命令
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "gen",
+		Short:         "RuleBack 代码生成工具",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(newModuleCommand())
+	return root
+}
+
+// newModuleCommand 生成CRUD业务模块
+func newModuleCommand() *cobra.Command {
+	var (
+		specPath string
+		fields   []string
+		spec     generator.ModuleSpec
+		gen      generator.Generator
+	)
+
+	cmd := &cobra.Command{
+		Use:   "module [name]",
+		Short: "生成CRUD模块（model/repository/service/handler/测试/模块定义）并注册Wire、模块列表和API文档",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s := &spec
+			if specPath != "" {
+				loaded, err := generator.LoadSpec(specPath)
+				if err != nil {
+					return err
+				}
+				mergeFlags(cmd, loaded, &spec)
+				s = loaded
+			}
+			if len(args) == 1 {
+				s.Name = args[0]
+			}
+			for _, f := range fields {
+				field, err := generator.ParseField(f)
+				if err != nil {
+					return err
+				}
+				s.Fields = append(s.Fields, field)
+			}
+
+			changes, err := gen.Generate(s)
+			if err != nil {
+				return err
+			}
+
+			for _, c := range changes {
+				fmt.Printf("%-9s %s\n", c.Action, c.Path)
+			}
+			if gen.DryRun {
+				fmt.Println("（dry-run，未写入文件）")
+				return nil
+			}
+			fmt.Println("\n完成。建议执行:")
+			fmt.Println("  cd internal/wire && wire      # 可选，重新生成 wire_gen.go")
+			fmt.Println("  go build ./... && go test ./internal/handler/...")
+			fmt.Println("  go run ./cmd/server migrate up")
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&specPath, "spec", "", "YAML格式的模块定义文件")
+	flags.StringArrayVar(&fields, "field", nil, "字段定义，格式 名称:类型[:选项,...]，可重复")
+	flags.StringVar(&spec.Comment, "comment", "", "模块中文名称，如 订单")
+	flags.StringVar(&spec.Table, "table", "", "表名，默认为名称复数")
+	flags.StringVar(&spec.Route, "route", "", "路由前缀，默认为 /名称复数")
+	flags.BoolVar(&spec.Public, "public", false, "注册为公开路由（默认需要认证）")
+	flags.StringVar(&gen.Root, "root", ".", "项目根目录")
+	flags.BoolVar(&gen.Force, "force", false, "覆盖已存在的生成文件")
+	flags.BoolVar(&gen.DryRun, "dry-run", false, "只输出将要生成和修改的文件")
+	return cmd
+}
+
+// mergeFlags 命令行显式指定的参数覆盖YAML中的值
+func mergeFlags(cmd *cobra.Command, dst, flagValues *generator.ModuleSpec) {
+	flags := cmd.Flags()
+	if flags.Changed("comment") {
+		dst.Comment = flagValues.Comment
+	}
+	if flags.Changed("table") {
+		dst.Table = flagValues.Table
+	}
+	if flags.Changed("route") {
+		dst.Route = flagValues.Route
+	}
+	if flags.Changed("public") {
+		dst.Public = flagValues.Public
+	}
+}
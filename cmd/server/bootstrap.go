@@ -10,34 +10,75 @@ import (
 	"syscall"
 	"time"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"gorm.io/gorm"
 	"ruleback/internal/config"
+	"ruleback/internal/modules"
+	"ruleback/internal/repository"
 	"ruleback/internal/router"
 	"ruleback/internal/wire"
 	"ruleback/pkg/database"
+	"ruleback/pkg/idgen"
 	"ruleback/pkg/logger"
+	"ruleback/pkg/module"
+	"ruleback/pkg/tlsconfig"
+	"ruleback/pkg/tracing"
 )
 
-// initApp 初始化应用程序
+// initApp 初始化应用程序（serve 命令使用）
 func initApp() error {
 	var err error
+	if err = initBase(); err != nil {
+		return err
+	}
 
-	cfg, err = config.Load(ConfigPath)
-	if err != nil {
-		return fmt.Errorf("加载配置失败: %w", err)
+	if err := initTracing(); err != nil {
+		return fmt.Errorf("初始化链路追踪失败: %w", err)
 	}
 
-	if err = initLogger(); err != nil {
-		return fmt.Errorf("初始化日志失败: %w", err)
+	if cfg.App.HotReload {
+		if err := watchConfig(); err != nil {
+			return fmt.Errorf("启动配置监听失败: %w", err)
+		}
 	}
 
-	if err = initDatabase(); err != nil {
+	if err := initDatabase(); err != nil {
 		return fmt.Errorf("初始化数据库失败: %w", err)
 	}
 
-	if err = migrateDatabase(); err != nil {
+	if handlers, registry, err = loadModules(database.GetDB()); err != nil {
+		return fmt.Errorf("初始化模块失败: %w", err)
+	}
+
+	if err := migrateDatabase(); err != nil {
 		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
 
+	if err := registry.Start(context.Background()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// initBase 加载配置并初始化日志和ID生成器，所有需要配置的子命令共用
+func initBase() error {
+	var err error
+
+	cfg, err = config.LoadWithOptions(configPath, loadOptions)
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+
+	if err = initLogger(); err != nil {
+		return fmt.Errorf("初始化日志失败: %w", err)
+	}
+
+	if err = idgen.SetSnowflakeNode(cfg.App.NodeID); err != nil {
+		return fmt.Errorf("初始化ID生成器失败: %w", err)
+	}
+
 	return nil
 }
 
@@ -50,73 +91,190 @@ func initLogger() error {
 	return nil
 }
 
+// initTracing 初始化链路追踪，未开启时只传播上游的trace上下文
+func initTracing() error {
+	if err := tracing.Init(&cfg.Tracing, &cfg.App); err != nil {
+		return err
+	}
+	if cfg.Tracing.Enabled {
+		logger.Info("链路追踪已开启",
+			logger.String("exporter", cfg.Tracing.Exporter),
+			logger.Float64("sample_ratio", cfg.Tracing.GetSampleRatio()),
+		)
+	}
+	return nil
+}
+
+// watchConfig 监听配置文件变化并热更新
+// 新增可热更新的配置时，在此订阅变更或在使用处通过 config.Get() 读取最新值
+func watchConfig() error {
+	config.Subscribe(func(old, new *config.Config) {
+		if old.Log.Level != new.Log.Level {
+			logger.SetLevel(new.Log.Level)
+			logger.Info("日志级别已更新", logger.String("from", old.Log.Level), logger.String("to", new.Log.Level))
+		}
+		if old.RateLimit != new.RateLimit {
+			logger.Info("限流配置已更新",
+				logger.Bool("enabled", new.RateLimit.Enabled),
+				logger.Int("requests", new.RateLimit.Requests),
+				logger.Int("window", new.RateLimit.Window),
+			)
+		}
+	})
+
+	err := config.Watch(func(err error) {
+		logger.Warn("配置热更新异常", logger.Err(err))
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("配置热更新已开启")
+	return nil
+}
+
 // initDatabase 初始化数据库连接
 func initDatabase() error {
 	if err := database.Init(&cfg.Database); err != nil {
 		return err
 	}
+	if err := repository.RegisterAuditTrail(database.GetDB()); err != nil {
+		return err
+	}
 	logger.Info("数据库连接初始化完成", logger.String("host", cfg.Database.Host))
 	return nil
 }
 
-// migrateDatabase 执行数据库迁移
-// 使用框架时，在此处添加你的模型进行自动迁移
-// 示例:
-//
-//	models := []interface{}{
-//	    &model.User{},
-//	    &model.Product{},
-//	}
-func migrateDatabase() error {
-	models := []interface{}{
-		// 在此添加你的模型
+// loadModules 使用Wire初始化所有Handler并注册模块
+// 模块列表在 internal/modules/modules.go 中维护；同时登记嵌入 model.AuditTrail 的模型对应的表
+// db 为nil时（如 routes 命令不连接数据库）只注册模块，不登记审计表
+func loadModules(db *gorm.DB) (*wire.Handlers, *module.Registry, error) {
+	h, err := wire.InitializeHandlers(db)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化Handler失败: %w", err)
 	}
 
-	if len(models) == 0 {
-		logger.Info("没有模型需要迁移")
-		return nil
+	r, err := module.NewRegistry(modules.All(db, h)...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if db == nil {
+		return h, r, nil
+	}
+
+	var models []interface{}
+	for _, m := range r.Modules() {
+		models = append(models, m.Models()...)
+	}
+	if err := repository.RegisterAuditedModels(db, models...); err != nil {
+		return nil, nil, err
 	}
+	return h, r, nil
+}
 
-	if err := database.AutoMigrate(models...); err != nil {
+// migrateDatabase 执行所有模块中未执行的数据库迁移，并确认模型对应的表已创建
+func migrateDatabase() error {
+	db := database.GetDB()
+	applied, err := database.MigrateUp(db, registry.Migrations())
+	if err != nil {
+		return err
+	}
+	if err := registry.CheckTables(db); err != nil {
 		return err
 	}
 
-	logger.Info("数据库迁移完成", logger.Int("models", len(models)))
+	logger.Info("数据库迁移完成", logger.Int("applied", len(applied)))
 	return nil
 }
 
 // startServer 启动HTTP服务器
-func startServer() *http.Server {
-	// 使用Wire初始化所有Handler
-	handlers, err := wire.InitializeHandlers(database.GetDB())
+// 启用TLS时使用热加载的证书提供HTTPS（自动支持HTTP/2），启用h2c时以明文提供HTTP/2
+func startServer() (*http.Server, error) {
+	var handler http.Handler = router.Setup(handlers, registry)
+	if cfg.Server.H2C {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	srv := &http.Server{
+		Addr:         cfg.Server.GetAddress(),
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+	}
+
+	if !cfg.Server.TLS.Enabled {
+		go serve(srv, "HTTP服务器", srv.ListenAndServe)
+		return srv, nil
+	}
+
+	reloader, err := tlsconfig.NewCertReloader(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
 	if err != nil {
-		logger.Fatal("初始化Handler失败", logger.Err(err))
+		return nil, err
+	}
+	if srv.TLSConfig, err = tlsconfig.New(&cfg.Server.TLS, reloader); err != nil {
+		return nil, err
 	}
+	if err := reloader.Watch(func(err error) {
+		logger.Warn("TLS证书热加载异常，继续使用原证书", logger.Err(err))
+	}); err != nil {
+		return nil, fmt.Errorf("启动证书监听失败: %w", err)
+	}
+	srv.RegisterOnShutdown(func() { reloader.Close() })
+
+	// 证书由 TLSConfig.GetCertificate 提供
+	go serve(srv, "HTTPS服务器", func() error { return srv.ListenAndServeTLS("", "") })
+	return srv, nil
+}
 
-	r := router.Setup(handlers)
+// startRedirectServer 启动HTTP到HTTPS的重定向服务器，未配置 server.tls.redirect_address 时返回nil
+func startRedirectServer() *http.Server {
+	if cfg.Server.TLS.RedirectAddress == "" {
+		return nil
+	}
 
 	srv := &http.Server{
-		Addr:         cfg.Server.GetAddress(),
-		Handler:      r,
+		Addr:         cfg.Server.TLS.RedirectAddress,
+		Handler:      tlsconfig.RedirectHandler(cfg.Server.Port),
 		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
-	go func() {
-		logger.Info("HTTP服务器启动",
-			logger.String("env", cfg.App.Env),
-			logger.String("address", cfg.Server.GetAddress()),
-		)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("HTTP服务器异常退出", logger.Err(err))
-		}
-	}()
+	go serve(srv, "HTTPS重定向服务器", srv.ListenAndServe)
+	return srv
+}
 
+// startAdminServer 启动运维服务器，未配置 server.admin_address 时返回nil
+func startAdminServer() *http.Server {
+	if !cfg.Server.IsAdminEnabled() {
+		return nil
+	}
+
+	// 运维端点可能长时间输出（如性能分析），不设置写超时
+	srv := &http.Server{
+		Addr:        cfg.Server.AdminAddress,
+		Handler:     router.SetupAdmin(registry),
+		ReadTimeout: time.Duration(cfg.Server.ReadTimeout) * time.Second,
+	}
+
+	go serve(srv, "运维服务器", srv.ListenAndServe)
 	return srv
 }
 
+// serve 监听并处理请求，异常退出时终止进程
+func serve(srv *http.Server, name string, listen func() error) {
+	logger.Info(name+"启动",
+		logger.String("env", cfg.App.Env),
+		logger.String("address", srv.Addr),
+	)
+	if err := listen(); err != nil && err != http.ErrServerClosed {
+		logger.Fatal(name+"异常退出", logger.Err(err))
+	}
+}
+
 // gracefulShutdown 优雅关闭服务器
-func gracefulShutdown(srv *http.Server) {
+// 先按传入顺序关闭服务器（主服务器在前，等待请求处理完成），最后关闭模块
+func gracefulShutdown(srv *http.Server, others ...*http.Server) {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
@@ -126,12 +284,23 @@ func gracefulShutdown(srv *http.Server) {
 	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error("HTTP服务器关闭异常", logger.Err(err))
+	for _, s := range append([]*http.Server{srv}, others...) {
+		if s == nil {
+			continue
+		}
+		if err := s.Shutdown(ctx); err != nil {
+			logger.Error("服务器关闭异常", logger.String("address", s.Addr), logger.Err(err))
+		}
+	}
+
+	// 按启动的相反顺序关闭模块，数据库连接由 database 模块最后关闭
+	if err := registry.Stop(ctx); err != nil {
+		logger.Error("模块关闭异常", logger.Err(err))
 	}
 
-	if err := database.Close(); err != nil {
-		logger.Error("数据库关闭异常", logger.Err(err))
+	// 导出剩余的span
+	if err := tracing.Shutdown(ctx); err != nil {
+		logger.Error("链路追踪关闭异常", logger.Err(err))
 	}
 
 	logger.Sync()
@@ -0,0 +1,394 @@
+// Package main 命令行子命令
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+	"ruleback/internal/config"
+	"ruleback/internal/router"
+	"ruleback/pkg/buildinfo"
+	"ruleback/pkg/database"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/tlsconfig"
+)
+
+// newRootCommand 创建根命令，未指定子命令时等同于 serve
+func newRootCommand() *cobra.Command {
+	var overrides []string
+
+	root := &cobra.Command{
+		Use:           "ruleback",
+		Short:         "RuleBack 服务端",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return parseOverrides(overrides)
+		},
+		RunE: runServe,
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&configPath, "config", ConfigPath, "基础配置文件路径")
+	flags.StringVar(&loadOptions.Env, "env", "", "运行环境，决定加载的 config.{env}.yaml")
+	flags.StringArrayVar(&overrides, "set", nil, "覆盖配置项，格式 key=value，可重复，如 --set server.port=9090")
+
+	root.AddCommand(
+		newServeCommand(),
+		newMigrateCommand(),
+		newSeedCommand(),
+		newRoutesCommand(),
+		newConfigCommand(),
+		newCertCommand(),
+		newVersionCommand(),
+	)
+	return root
+}
+
+// parseOverrides 解析 --set 参数
+func parseOverrides(pairs []string) error {
+	loadOptions.Overrides = make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("--set 格式应为 key=value: %q", pair)
+		}
+		loadOptions.Overrides[key] = value
+	}
+	return nil
+}
+
+// newServeCommand 启动HTTP服务器
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "启动HTTP服务器（启动前自动执行未执行的迁移）",
+		Args:  cobra.NoArgs,
+		RunE:  runServe,
+	}
+}
+
+// runServe 初始化应用、启动服务器并等待关闭信号
+func runServe(cmd *cobra.Command, args []string) error {
+	// 1. 初始化应用
+	if err := initApp(); err != nil {
+		return fmt.Errorf("应用初始化失败: %w", err)
+	}
+
+	// 2. 启动服务器（按配置同时启动运维服务器和HTTPS重定向服务器）
+	srv, err := startServer()
+	if err != nil {
+		return fmt.Errorf("启动服务器失败: %w", err)
+	}
+	adminSrv := startAdminServer()
+	redirectSrv := startRedirectServer()
+
+	// 3. 等待关闭信号
+	gracefulShutdown(srv, adminSrv, redirectSrv)
+	return nil
+}
+
+// newMigrateCommand 数据库迁移命令组
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "数据库迁移",
+	}
+
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "执行所有未执行的迁移",
+		Args:  cobra.NoArgs,
+		RunE: withMigrations(func(db *gorm.DB, migrations []database.Migration) error {
+			applied, err := database.MigrateUp(db, migrations)
+			printMigrations("已执行", applied)
+			return err
+		}),
+	}
+
+	var steps int
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "回滚最近执行的迁移",
+		Args:  cobra.NoArgs,
+		RunE: withMigrations(func(db *gorm.DB, migrations []database.Migration) error {
+			rolledBack, err := database.MigrateDown(db, migrations, steps)
+			printMigrations("已回滚", rolledBack)
+			return err
+		}),
+	}
+	down.Flags().IntVar(&steps, "steps", 1, "回滚的迁移数量")
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "查看迁移执行状态",
+		Args:  cobra.NoArgs,
+		RunE: withMigrations(func(db *gorm.DB, migrations []database.Migration) error {
+			statuses, err := database.MigrationStatuses(db, migrations)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSTATUS\tAPPLIED AT")
+			for _, s := range statuses {
+				if s.Applied {
+					fmt.Fprintf(w, "%s\tapplied\t%s\n", s.ID, s.AppliedAt.Format("2006-01-02 15:04:05"))
+				} else {
+					fmt.Fprintf(w, "%s\tpending\t-\n", s.ID)
+				}
+			}
+			return w.Flush()
+		}),
+	}
+
+	cmd.AddCommand(up, down, status)
+	return cmd
+}
+
+// printMigrations 输出本次处理的迁移
+func printMigrations(action string, ids []string) {
+	if len(ids) == 0 {
+		fmt.Printf("%s 0 个迁移\n", action)
+		return
+	}
+	for _, id := range ids {
+		fmt.Printf("%s: %s\n", action, id)
+	}
+}
+
+// newSeedCommand 写入初始化数据
+func newSeedCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "seed [name...]",
+		Short: "写入初始化数据，不指定名称时执行全部",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selected, err := selectSeeders(args)
+			if err != nil {
+				return err
+			}
+
+			return withDatabase(func(db *gorm.DB) error {
+				ctx := cmd.Context()
+				for _, s := range selected {
+					if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+						return s.run(ctx, tx)
+					}); err != nil {
+						return fmt.Errorf("初始化数据 %s 写入失败: %w", s.name, err)
+					}
+					fmt.Printf("已写入: %s\n", s.name)
+				}
+				return nil
+			})(cmd, args)
+		},
+	}
+}
+
+// selectSeeders 按名称筛选初始化数据
+func selectSeeders(names []string) ([]seeder, error) {
+	all := seeders()
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	byName := make(map[string]seeder, len(all))
+	for _, s := range all {
+		byName[s.name] = s
+	}
+
+	selected := make([]seeder, 0, len(names))
+	for _, name := range names {
+		s, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("初始化数据 %s 不存在", name)
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
+
+// newRoutesCommand 列出已注册的路由，不连接数据库
+func newRoutesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "routes",
+		Short: "列出已注册的路由",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := initBase(); err != nil {
+				return err
+			}
+
+			h, r, err := loadModules(nil)
+			if err != nil {
+				return err
+			}
+
+			gin.SetMode(gin.ReleaseMode)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SERVER\tMETHOD\tPATH\tHANDLER")
+			for _, route := range router.Setup(h, r).Routes() {
+				fmt.Fprintf(w, "public\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+			}
+			if cfg.Server.IsAdminEnabled() {
+				for _, route := range router.SetupAdmin(r).Routes() {
+					fmt.Fprintf(w, "admin\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler)
+				}
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newConfigCommand 配置检查命令组
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "配置检查",
+	}
+
+	check := &cobra.Command{
+		Use:   "check",
+		Short: "加载并校验配置",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			loaded, err := config.LoadWithOptions(configPath, loadOptions)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("配置校验通过（env: %s）\n", loaded.App.Env)
+			return nil
+		},
+	}
+
+	printCmd := &cobra.Command{
+		Use:   "print",
+		Short: "输出合并后的生效配置及来源（敏感值已脱敏）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := config.LoadWithOptions(configPath, loadOptions); err != nil {
+				return err
+			}
+			return config.PrintEffective(os.Stdout)
+		},
+	}
+
+	cmd.AddCommand(check, printCmd)
+	return cmd
+}
+
+// newCertCommand 生成本地开发和测试用的自签名证书
+func newCertCommand() *cobra.Command {
+	var (
+		hosts    []string
+		client   string
+		outDir   string
+		validFor time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cert",
+		Short: "生成自签名证书（仅用于本地开发和测试）",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return err
+			}
+
+			if client != "" {
+				certPEM, keyPEM, err := tlsconfig.GenerateClientCert(client, validFor)
+				if err != nil {
+					return err
+				}
+				certFile, keyFile, err := writeCertPair(outDir, client, certPEM, keyPEM)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("启用方式: --set server.tls.client_auth.mode=required --set server.tls.client_auth.ca_file=%s\n", certFile)
+				fmt.Printf("客户端使用: curl --cert %s --key %s ...\n", certFile, keyFile)
+				return nil
+			}
+
+			certPEM, keyPEM, err := tlsconfig.GenerateSelfSigned(hosts, validFor)
+			if err != nil {
+				return err
+			}
+			certFile, keyFile, err := writeCertPair(outDir, "", certPEM, keyPEM)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("启用方式: --set server.tls.enabled=true --set server.tls.cert_file=%s --set server.tls.key_file=%s\n", certFile, keyFile)
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&hosts, "host", []string{"localhost", "127.0.0.1", "::1"}, "服务端证书包含的域名或IP，可用逗号分隔")
+	flags.StringVar(&client, "client", "", "生成客户端证书（mTLS），值为证书中的身份，如 billing-service")
+	flags.StringVar(&outDir, "out", "configs/certs", "输出目录")
+	flags.DurationVar(&validFor, "valid-for", 365*24*time.Hour, "有效期")
+	return cmd
+}
+
+// writeCertPair 写入证书和私钥，name 为空时写入 cert.pem/key.pem，否则写入 {name}.pem/{name}-key.pem
+func writeCertPair(dir, name string, certPEM, keyPEM []byte) (certFile, keyFile string, err error) {
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if name != "" {
+		certFile, keyFile = filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	}
+
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return "", "", err
+	}
+	fmt.Printf("已生成: %s\n已生成: %s\n", certFile, keyFile)
+	return certFile, keyFile, nil
+}
+
+// newVersionCommand 输出构建信息
+func newVersionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "输出版本和构建信息",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println(buildinfo.Get())
+		},
+	}
+}
+
+// withDatabase 初始化配置、日志和数据库后执行维护操作，结束时关闭连接
+func withDatabase(fn func(db *gorm.DB) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := initBase(); err != nil {
+			return err
+		}
+		defer logger.Sync()
+
+		if err := initDatabase(); err != nil {
+			return fmt.Errorf("初始化数据库失败: %w", err)
+		}
+		defer database.Close()
+
+		return fn(database.GetDB())
+	}
+}
+
+// withMigrations 在 withDatabase 的基础上收集所有模块的迁移
+func withMigrations(fn func(db *gorm.DB, migrations []database.Migration) error) func(cmd *cobra.Command, args []string) error {
+	return withDatabase(func(db *gorm.DB) error {
+		_, r, err := loadModules(db)
+		if err != nil {
+			return err
+		}
+		return fn(db, r.Migrations())
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"ruleback/internal/config"
+	"ruleback/internal/wire"
+	"ruleback/pkg/module"
 )
 
 const (
@@ -14,18 +16,18 @@ const (
 	ShutdownTimeout = 5 * time.Second
 )
 
-var cfg *config.Config
+var (
+	cfg         *config.Config
+	configPath  string
+	loadOptions config.LoadOptions
+	handlers    *wire.Handlers
+	registry    *module.Registry
+)
 
 func main() {
-	// 1. 初始化应用
-	if err := initApp(); err != nil {
-		fmt.Printf("应用初始化失败: %v\n", err)
+	// 解析命令行并执行子命令，未指定子命令时启动服务器（serve）
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Printf("执行失败: %v\n", err)
 		os.Exit(1)
 	}
-
-	// 2. 启动服务器
-	srv := startServer()
-
-	// 3. 等待关闭信号
-	gracefulShutdown(srv)
 }
@@ -0,0 +1,27 @@
+// Package main 初始化数据列表
+package main
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// seeder 一组初始化数据
+type seeder struct {
+	name string
+	run  func(ctx context.Context, db *gorm.DB) error
+}
+
+// seeders 返回所有初始化数据，seed 命令按顺序在各自的事务中执行
+// 初始化数据可能被重复执行，应使用 FirstOrCreate 等方式保证幂等
+// 示例:
+//
+//	{name: "admin_user", run: func(ctx context.Context, db *gorm.DB) error {
+//	    return db.WithContext(ctx).Where(model.User{Username: "admin"}).FirstOrCreate(&model.User{}).Error
+//	}},
+func seeders() []seeder {
+	return []seeder{
+		// 在此添加你的初始化数据
+	}
+}
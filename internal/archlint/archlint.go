@@ -0,0 +1,246 @@
+// Package archlint 检查代码是否遵守各层 RULE.md 中的分层规则
+package archlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ignoreDirective 忽略所在行或下一行违规的注释，需注明原因
+const ignoreDirective = "//archlint:ignore"
+
+// Diagnostic 违规信息
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// String 返回 文件:行:列: 信息 格式，与 go vet 输出一致
+func (d Diagnostic) String() string {
+	return d.Pos.String() + ": " + d.Message
+}
+
+// layers 受检查的分层，按依赖方向从低到高排列
+var layers = []string{"model", "repository", "service", "handler"}
+
+// allowedLayers 每层允许导入的其他层，未列出的层禁止导入
+var allowedLayers = map[string][]string{
+	"model":      {},
+	"repository": {"model"},
+	"service":    {"repository", "model"},
+	"handler":    {"service", "model"},
+}
+
+// upperPackages 负责组装的上层包，分层代码禁止导入
+var upperPackages = []string{"router", "wire"}
+
+// forbiddenImport 某层禁止导入的外部包
+type forbiddenImport struct {
+	path   string // 包路径，子包同样禁止
+	suffix bool   // 为true时按路径后缀匹配，用于本项目的包
+	reason string
+}
+
+// forbiddenImports 每层禁止导入的包
+var forbiddenImports = map[string][]forbiddenImport{
+	"model": {
+		{path: "github.com/gin-gonic/gin", reason: "模型不能依赖HTTP框架"},
+	},
+	"repository": {
+		{path: "github.com/gin-gonic/gin", reason: "Repository不能依赖HTTP框架"},
+	},
+	"service": {
+		{path: "github.com/gin-gonic/gin", reason: "Service应接收业务参数，不能依赖gin"},
+	},
+	"handler": {
+		{path: "gorm.io/gorm", reason: "Handler不能直接操作数据库，应通过Service调用"},
+		{path: "/pkg/database", suffix: true, reason: "Handler不能直接操作数据库，应通过Service调用"},
+	},
+}
+
+// responseMethods 绕过response包直接输出JSON的gin.Context方法
+var responseMethods = map[string]bool{
+	"JSON":                true,
+	"IndentedJSON":        true,
+	"SecureJSON":          true,
+	"PureJSON":            true,
+	"AsciiJSON":           true,
+	"JSONP":               true,
+	"AbortWithStatusJSON": true,
+}
+
+// CheckPackage 检查单个包，返回按位置排序的违规，测试文件不检查
+// info 需包含 Types，用于识别 gin.Context 的方法调用
+func CheckPackage(fset *token.FileSet, pkgPath string, files []*ast.File, info *types.Info) []Diagnostic {
+	layer := layerOf(pkgPath)
+	isResponsePkg := strings.HasSuffix(pkgPath, "/pkg/response")
+
+	var diags []Diagnostic
+	for _, file := range files {
+		filename := fset.Position(file.Pos()).Filename
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+
+		r := &reporter{fset: fset, info: info, ignored: ignoredLines(fset, file)}
+		if layer != "" {
+			checkImports(r, file, layer)
+		}
+		if !isResponsePkg {
+			checkResponseCalls(r, file)
+		}
+		diags = append(diags, r.diags...)
+	}
+
+	sort.Slice(diags, func(i, j int) bool {
+		if diags[i].Pos.Filename != diags[j].Pos.Filename {
+			return diags[i].Pos.Filename < diags[j].Pos.Filename
+		}
+		return diags[i].Pos.Offset < diags[j].Pos.Offset
+	})
+	return diags
+}
+
+// checkImports 检查导入方向和禁止导入的包
+func checkImports(r *reporter, file *ast.File, layer string) {
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		if imported := layerOf(path); imported != "" && imported != layer && !contains(allowedLayers[layer], imported) {
+			r.report(spec.Pos(), "%s 层禁止导入 %s 层（%s），允许导入: %s",
+				layer, imported, path, allowedList(layer))
+			continue
+		}
+		for _, upper := range upperPackages {
+			if internalPackage(path, upper) {
+				r.report(spec.Pos(), "%s 层禁止导入 %s（%s 负责组装各层，只能被上层使用）", layer, path, upper)
+			}
+		}
+		for _, f := range forbiddenImports[layer] {
+			if f.matches(path) {
+				r.report(spec.Pos(), "%s 层禁止导入 %s: %s", layer, path, f.reason)
+			}
+		}
+	}
+}
+
+// checkResponseCalls 检查直接调用 gin.Context 的JSON输出方法
+func checkResponseCalls(r *reporter, file *ast.File) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || !responseMethods[sel.Sel.Name] {
+			return true
+		}
+		if isGinContext(r.info.TypeOf(sel.X)) {
+			r.report(call.Pos(), "禁止直接调用 %s，请使用 pkg/response 统一响应格式", sel.Sel.Name)
+		}
+		return true
+	})
+}
+
+// isGinContext 判断类型是否为 *gin.Context 或 gin.Context
+func isGinContext(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "github.com/gin-gonic/gin" && obj.Name() == "Context"
+}
+
+// layerOf 返回包所属的分层，不属于任何分层时返回空字符串
+// 分层子包和外部测试包归属于所在分层
+func layerOf(pkgPath string) string {
+	pkgPath = strings.TrimSuffix(pkgPath, "_test")
+	for _, layer := range layers {
+		if internalPackage(pkgPath, layer) {
+			return layer
+		}
+	}
+	return ""
+}
+
+// internalPackage 判断包路径是否为 internal/<name> 或其子包
+func internalPackage(pkgPath, name string) bool {
+	dir := "internal/" + name
+	return pkgPath == dir || strings.HasSuffix(pkgPath, "/"+dir) ||
+		strings.HasPrefix(pkgPath, dir+"/") || strings.Contains(pkgPath, "/"+dir+"/")
+}
+
+// matches 判断导入路径是否命中禁止规则
+func (f forbiddenImport) matches(path string) bool {
+	if f.suffix {
+		return strings.HasSuffix(path, f.path) || strings.Contains(path, f.path+"/")
+	}
+	return path == f.path || strings.HasPrefix(path, f.path+"/")
+}
+
+// allowedList 返回某层允许导入的分层说明
+func allowedList(layer string) string {
+	if len(allowedLayers[layer]) == 0 {
+		return "无"
+	}
+	return strings.Join(allowedLayers[layer], "、")
+}
+
+// contains 判断切片是否包含指定字符串
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// reporter 收集单个文件的违规并跳过被忽略的行
+type reporter struct {
+	fset    *token.FileSet
+	info    *types.Info
+	ignored map[int]bool
+	diags   []Diagnostic
+}
+
+// report 记录违规，位置包含文件名和行号
+func (r *reporter) report(pos token.Pos, format string, args ...interface{}) {
+	position := r.fset.Position(pos)
+	if r.ignored[position.Line] {
+		return
+	}
+	r.diags = append(r.diags, Diagnostic{Pos: position, Message: fmt.Sprintf(format, args...)})
+}
+
+// ignoredLines 返回被 //archlint:ignore 忽略的行号
+// 注释单独一行时忽略下一行，位于行尾时忽略所在行
+func ignoredLines(fset *token.FileSet, file *ast.File) map[int]bool {
+	lines := make(map[int]bool)
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, ignoreDirective) {
+				continue
+			}
+			line := fset.Position(c.Pos()).Line
+			lines[line] = true
+			lines[line+1] = true
+		}
+	}
+	return lines
+}
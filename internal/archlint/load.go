@@ -0,0 +1,136 @@
+package archlint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// listedPackage go list -json 输出中需要的字段
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	ImportMap  map[string]string
+	DepOnly    bool
+	Error      *struct{ Err string }
+}
+
+// Run 加载匹配的包并检查，包路径格式与 go vet 相同，如 ./...
+// 依赖包的类型信息来自编译器导出数据，因此与当前Go工具链版本一致
+// 包无法编译时仍返回已发现的违规，同时返回加载错误
+func Run(patterns ...string) ([]Diagnostic, error) {
+	pkgs, err := listPackages(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make(map[string]string, len(pkgs))
+	for _, p := range pkgs {
+		if p.Export != "" {
+			exports[p.ImportPath] = p.Export
+		}
+	}
+
+	fset := token.NewFileSet()
+	gcImporter := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("缺少 %s 的导出数据", path)
+		}
+		return os.Open(file)
+	})
+
+	var diags []Diagnostic
+	var errs []error
+	for _, p := range pkgs {
+		if p.DepOnly {
+			continue
+		}
+		// 存在导入循环等加载错误时仍检查导入方向，违规往往正是错误的原因
+		if p.Error != nil {
+			errs = append(errs, fmt.Errorf("加载 %s 失败: %s", p.ImportPath, p.Error.Err))
+		}
+
+		files := make([]*ast.File, 0, len(p.GoFiles))
+		for _, name := range p.GoFiles {
+			file, err := parser.ParseFile(fset, filepath.Join(p.Dir, name), nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+
+		// 类型信息不完整时JSON输出检查可能漏报，因此类型错误同样返回
+		var typeErr error
+		info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+		conf := types.Config{
+			Importer: mappedImporter{importer: gcImporter.(types.ImporterFrom), importMap: p.ImportMap},
+			Error: func(err error) {
+				if typeErr == nil {
+					typeErr = err
+				}
+			},
+		}
+		_, _ = conf.Check(p.ImportPath, fset, files, info)
+		if typeErr != nil && p.Error == nil {
+			errs = append(errs, fmt.Errorf("类型检查 %s 失败: %w", p.ImportPath, typeErr))
+		}
+
+		diags = append(diags, CheckPackage(fset, p.ImportPath, files, info)...)
+	}
+	return diags, errors.Join(errs...)
+}
+
+// listPackages 执行 go list 获取匹配的包及其全部依赖
+func listPackages(patterns []string) ([]listedPackage, error) {
+	args := append([]string{"list", "-e", "-json", "-export", "-deps", "--"}, patterns...)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go list 执行失败: %v\n%s", err, stderr.String())
+	}
+
+	var pkgs []listedPackage
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var p listedPackage
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("解析 go list 输出失败: %w", err)
+		}
+		pkgs = append(pkgs, p)
+	}
+	return pkgs, nil
+}
+
+// mappedImporter 按 go list 的 ImportMap 转换导入路径（vendor等情况）
+type mappedImporter struct {
+	importer  types.ImporterFrom
+	importMap map[string]string
+}
+
+// Import 导入包
+func (m mappedImporter) Import(path string) (*types.Package, error) {
+	return m.ImportFrom(path, "", 0)
+}
+
+// ImportFrom 导入包，路径先经过 ImportMap 转换
+func (m mappedImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if mapped, ok := m.importMap[path]; ok {
+		path = mapped
+	}
+	return m.importer.ImportFrom(path, dir, mode)
+}
@@ -3,96 +3,251 @@ package config
 
 import (
 	"fmt"
-	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
-var globalConfig *Config
+var (
+	globalConfig atomic.Pointer[Config]
+	loaded       atomic.Pointer[loadState]
+)
 
 // Config 应用程序根配置结构体
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Log      LogConfig      `mapstructure:"log"`
-	JWT      *JWTConfig     `mapstructure:"jwt"` // 可选配置
+	App         AppConfig         `mapstructure:"app"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Log         LogConfig         `mapstructure:"log"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit"`  // 请求限流配置，支持热更新
+	CORS        CORSConfig        `mapstructure:"cors"`        // 跨域配置，支持热更新
+	Tracing     TracingConfig     `mapstructure:"tracing"`     // 链路追踪配置
+	Diagnostics DiagnosticsConfig `mapstructure:"diagnostics"` // 运行时诊断端点配置
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"` // 幂等键配置
+	HTTPCache   HTTPCacheConfig   `mapstructure:"http_cache"`  // 服务端响应缓存配置
+	JWT         *JWTConfig        `mapstructure:"jwt"`         // 可选配置
+	Features    map[string]bool   `mapstructure:"features"`    // 功能开关，支持热更新
 }
 
 // AppConfig 应用基础配置
 type AppConfig struct {
-	Name  string `mapstructure:"name"`
-	Env   string `mapstructure:"env"`
-	Debug bool   `mapstructure:"debug"`
+	Name      string `mapstructure:"name" validate:"required"`
+	Env       string `mapstructure:"env" validate:"oneof=development staging production"`
+	Debug     bool   `mapstructure:"debug"`
+	NodeID    int64  `mapstructure:"node_id" validate:"gte=0,lte=1023"` // Snowflake节点ID，多实例部署时必须唯一
+	HotReload bool   `mapstructure:"hot_reload"`                        // 监听配置文件变化并热更新
 }
 
 // ServerConfig HTTP服务器配置
 type ServerConfig struct {
-	Host         string `mapstructure:"host"`
-	Port         int    `mapstructure:"port"`
-	ReadTimeout  int    `mapstructure:"read_timeout"`
-	WriteTimeout int    `mapstructure:"write_timeout"`
+	Host         string    `mapstructure:"host"`
+	Port         int       `mapstructure:"port" validate:"min=1,max=65535"`
+	ReadTimeout  int       `mapstructure:"read_timeout" validate:"min=1"`
+	WriteTimeout int       `mapstructure:"write_timeout" validate:"min=1"`
+	AdminAddress string    `mapstructure:"admin_address" validate:"omitempty,hostname_port"` // 运维服务器监听地址，如 127.0.0.1:9090，为空时不单独启动
+	H2C          bool      `mapstructure:"h2c"`                                              // 明文HTTP/2，用于部署在不支持TLS转发的代理之后
+	TLS          TLSConfig `mapstructure:"tls"`
+}
+
+// TLSConfig HTTPS配置，证书文件变化时自动重新加载
+type TLSConfig struct {
+	Enabled         bool             `mapstructure:"enabled"`
+	CertFile        string           `mapstructure:"cert_file" validate:"required_if=Enabled true"`
+	KeyFile         string           `mapstructure:"key_file" validate:"required_if=Enabled true"`
+	MinVersion      string           `mapstructure:"min_version" validate:"omitempty,oneof=1.2 1.3"`               // 最低TLS版本
+	CipherPolicy    string           `mapstructure:"cipher_policy" validate:"omitempty,oneof=intermediate modern"` // intermediate: TLS1.2+ECDHE+AEAD，modern: 仅TLS1.3
+	RedirectAddress string           `mapstructure:"redirect_address" validate:"omitempty,hostname_port"`          // HTTP重定向到HTTPS的监听地址，如 :80，为空时不启动
+	ClientAuth      ClientAuthConfig `mapstructure:"client_auth"`
+}
+
+// ClientAuthConfig 客户端证书认证（mTLS）配置
+type ClientAuthConfig struct {
+	Mode       string            `mapstructure:"mode" validate:"omitempty,oneof=none optional required"`                       // none: 不请求证书，optional: 提供时校验，required: 必须提供
+	CAFile     string            `mapstructure:"ca_file"`                                                                      // 信任的CA证书（PEM，可包含多个）
+	IdentityBy string            `mapstructure:"identity_by" validate:"omitempty,oneof=common_name dns_san uri_san email_san"` // 身份取自证书的哪个字段
+	Principals []ClientPrincipal `mapstructure:"principals" validate:"dive"`                                                   // 允许访问的身份，为空时接受所有受信任的证书
+}
+
+// ClientPrincipal 允许访问的客户端身份
+type ClientPrincipal struct {
+	Name   string `mapstructure:"name" validate:"required"` // 证书中的身份，如 billing-service 或 spiffe://example.org/billing
+	UserID uint   `mapstructure:"user_id"`                  // 映射的用户ID，写入审计字段，为0时不设置
+}
+
+// IsEnabled 是否请求客户端证书
+func (c *ClientAuthConfig) IsEnabled() bool {
+	return c.Mode != "" && c.Mode != "none"
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Driver          string `mapstructure:"driver"`
-	Host            string `mapstructure:"host"`
-	Port            int    `mapstructure:"port"`
-	Database        string `mapstructure:"database"`
-	Username        string `mapstructure:"username"`
+	Driver          string `mapstructure:"driver" validate:"required,oneof=mysql postgres"`
+	Host            string `mapstructure:"host" validate:"required"`
+	Port            int    `mapstructure:"port" validate:"min=1,max=65535"`
+	Database        string `mapstructure:"database" validate:"required"`
+	Username        string `mapstructure:"username" validate:"required"`
 	Password        string `mapstructure:"password"`
-	MaxOpenConns    int    `mapstructure:"max_open_conns"`
-	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
-	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+	MaxOpenConns    int    `mapstructure:"max_open_conns" validate:"min=1"`
+	MaxIdleConns    int    `mapstructure:"max_idle_conns" validate:"min=0,ltefield=MaxOpenConns"`
+	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime" validate:"min=0"`
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level    string `mapstructure:"level"`
-	Format   string `mapstructure:"format"`
-	Output   string `mapstructure:"output"`
-	FilePath string `mapstructure:"file_path"`
+	Level    string          `mapstructure:"level" validate:"oneof=debug info warn error"`
+	Format   string          `mapstructure:"format" validate:"oneof=json console"`
+	Output   string          `mapstructure:"output" validate:"oneof=stdout file"`
+	FilePath string          `mapstructure:"file_path" validate:"required_if=Output file"`
+	Access   AccessLogConfig `mapstructure:"access"` // 访问日志配置，支持热更新
+	Body     BodyLogConfig   `mapstructure:"body"`   // 请求/响应体日志配置，支持热更新
+}
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	Format       string              `mapstructure:"format" validate:"oneof=structured combined common"` // structured: 按 log.format 输出结构化字段；combined/common: Apache文本格式
+	ExcludePaths []string            `mapstructure:"exclude_paths"`                                      // 不记录的路径，精确匹配，以 * 结尾时按前缀匹配
+	Sampling     []AccessLogSampling `mapstructure:"sampling" validate:"dive"`                           // 高频路由的采样比例，4xx/5xx请求始终记录
+}
+
+// AccessLogSampling 单个路由的访问日志采样规则
+type AccessLogSampling struct {
+	Route string  `mapstructure:"route" validate:"required"`  // 路由模板，如 /api/v1/products/:id
+	Rate  float64 `mapstructure:"rate" validate:"gt=0,lte=1"` // 记录比例
+}
+
+// BodyLogConfig 请求/响应体日志配置，用于排查客户端对接问题，默认关闭
+// 内置脱敏 password、token、secret 等字段和 Authorization、Cookie 等请求头，配置项在此基础上追加
+type BodyLogConfig struct {
+	Enabled       bool     `mapstructure:"enabled"`
+	Routes        []string `mapstructure:"routes"`                     // 记录的路由模板，为空时所有路由
+	ErrorsOnly    bool     `mapstructure:"errors_only"`                // 只记录4xx/5xx响应
+	MaxBytes      int      `mapstructure:"max_bytes" validate:"gte=0"` // 单个请求体或响应体的最大记录字节数
+	RedactFields  []string `mapstructure:"redact_fields"`              // 追加脱敏的JSON路径，如 card_no、user.id_card、items.*.cvv
+	RedactHeaders []string `mapstructure:"redact_headers"`             // 追加脱敏的请求头和响应头
+}
+
+// RateLimitConfig 请求限流配置，由 middleware.RateLimit 使用，按客户端IP计数
+type RateLimitConfig struct {
+	Enabled  bool `mapstructure:"enabled"`
+	Requests int  `mapstructure:"requests" validate:"gte=0"` // 每个客户端在窗口内允许的请求数，0 表示使用 RateLimit 的参数
+	Window   int  `mapstructure:"window" validate:"gte=0"`   // 窗口秒数，0 表示使用 RateLimit 的参数
+}
+
+// CORSConfig 跨域配置
+// 来源支持精确匹配（https://app.example.com）和子域名通配（https://*.example.com，不匹配 example.com 本身）
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`          // 为空时不允许跨域请求；"*" 允许任意来源，不能与 allow_credentials 同时使用
+	AllowedMethods   []string `mapstructure:"allowed_methods"`          // 预检请求允许的方法
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`          // 预检请求允许的请求头，"*" 允许任意请求头
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`          // 浏览器可读取的响应头，始终包含 X-Request-ID
+	AllowCredentials bool     `mapstructure:"allow_credentials"`        // 允许携带Cookie等凭证
+	MaxAge           int      `mapstructure:"max_age" validate:"gte=0"` // 预检结果缓存秒数
+}
+
+// TracingConfig 链路追踪配置，使用 OpenTelemetry 和 W3C Trace Context 传播
+type TracingConfig struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Exporter    string   `mapstructure:"exporter" validate:"omitempty,oneof=otlp stdout file"` // otlp: 发送到采集器，stdout/file: 本地输出，用于离线调试
+	Endpoint    string   `mapstructure:"endpoint" validate:"required_if=Exporter otlp"`        // OTLP/HTTP 地址，如 localhost:4318
+	Insecure    bool     `mapstructure:"insecure"`                                             // OTLP 使用HTTP而非HTTPS
+	FilePath    string   `mapstructure:"file_path" validate:"required_if=Exporter file"`       // file 导出器的输出文件，每行一个JSON格式的span
+	SampleRatio *float64 `mapstructure:"sample_ratio" validate:"omitempty,gte=0,lte=1"`        // 新trace的采样比例，未配置时为1，0表示只跟随上游的采样决定
+}
+
+// DiagnosticsConfig 运行时诊断端点配置（pprof、goroutine、GC、构建信息）
+type DiagnosticsConfig struct {
+	Enabled *bool  `mapstructure:"enabled"` // 未配置时只在开发环境开启
+	Token   string `mapstructure:"token"`   // 访问令牌，请求头 X-Diagnostics-Token 携带；未配置时诊断端点只注册在运维服务器上
+}
+
+// IdempotencyConfig 幂等键配置，由 middleware.Idempotency 使用
+type IdempotencyConfig struct {
+	Store        string `mapstructure:"store" validate:"oneof=memory database"` // memory: 进程内，只适用于单实例；database: idempotency_keys 表，多实例共享
+	TTL          int    `mapstructure:"ttl" validate:"gt=0"`                    // 记录保留秒数，过期后同一个键按新请求处理
+	LockTimeout  int    `mapstructure:"lock_timeout" validate:"gt=0"`           // 处理中记录的占用秒数，超过后视为处理该请求的实例已退出，同一个键可重新执行；应大于 server.write_timeout
+	MaxBodyBytes int    `mapstructure:"max_body_bytes" validate:"gt=0"`         // 携带幂等键的请求体上限，超过时返回413
+}
+
+// HTTPCacheConfig 服务端响应缓存配置，由 middleware.Cache 使用，默认关闭
+// ETag 和条件请求由 middleware.ETag 处理，不受此配置影响
+type HTTPCacheConfig struct {
+	Enabled    bool             `mapstructure:"enabled"`                      // 关闭时 middleware.Cache 直接放行，支持热更新
+	MaxEntries int              `mapstructure:"max_entries" validate:"gte=1"` // 进程内缓存的最大条数，超过时淘汰最久未使用的，未配置时为10000，修改后需重启
+	Routes     []HTTPCacheRoute `mapstructure:"routes" validate:"dive"`       // 覆盖代码中指定的缓存时间，支持热更新
+}
+
+// HTTPCacheRoute 单个路由的缓存时间
+type HTTPCacheRoute struct {
+	Route string `mapstructure:"route" validate:"required"` // 路由模板，如 /api/v1/products/:id
+	TTL   int    `mapstructure:"ttl" validate:"gte=0"`      // 缓存秒数，0 表示不缓存
 }
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	ExpireTime int    `mapstructure:"expire_time"`
+	Secret     string `mapstructure:"secret" validate:"required"`
+	ExpireTime int    `mapstructure:"expire_time" validate:"min=1"`
 	Issuer     string `mapstructure:"issuer"`
 }
 
-// Load 从指定路径加载配置文件
+// Load 从指定路径加载配置文件，并设置为全局配置
+// 同目录下的 config.{env}.yaml 和 config.local.yaml 存在时依次覆盖，规则见 LoadWithOptions
 func Load(configPath string) (*Config, error) {
-	v := viper.New()
+	return LoadWithOptions(configPath, LoadOptions{})
+}
+
+// LoadWithOptions 按分层规则加载配置，并设置为全局配置
+// 优先级从低到高: 默认值 < config.yaml < config.{env}.yaml < config.local.yaml < 环境变量 < 命令行覆盖
+func LoadWithOptions(configPath string, opts LoadOptions) (*Config, error) {
+	cfg, state, err := load(configPath, opts)
+	if err != nil {
+		return nil, err
+	}
 
-	v.SetConfigFile(configPath)
-	v.SetEnvPrefix("APP")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	v.AutomaticEnv()
+	loaded.Store(state)
+	globalConfig.Store(cfg)
 
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	return cfg, nil
+}
+
+// load 合并、解析并校验配置
+func load(configPath string, opts LoadOptions) (*Config, *loadState, error) {
+	l, state, err := loadLayers(configPath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v := viper.New()
+	for key, value := range l.values {
+		v.Set(key, value)
 	}
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("解析配置失败: %w", err)
+		return nil, nil, fmt.Errorf("解析配置失败: %w", err)
 	}
 
 	setDefaults(&cfg)
-	globalConfig = &cfg
 
-	return &cfg, nil
+	if err := Validate(&cfg); err != nil {
+		return nil, nil, err
+	}
+
+	return &cfg, state, nil
 }
 
 // Get 获取全局配置实例
+// 开启热更新后每次调用可能返回新的实例，调用方不应长期持有返回值
 func Get() *Config {
-	return globalConfig
+	return globalConfig.Load()
 }
 
 // setDefaults 设置配置默认值
 func setDefaults(cfg *Config) {
+	if cfg.App.Env == "" {
+		cfg.App.Env = "development"
+	}
+
 	if cfg.Server.Host == "" {
 		cfg.Server.Host = "0.0.0.0"
 	}
@@ -105,12 +260,59 @@ func setDefaults(cfg *Config) {
 	if cfg.Server.WriteTimeout == 0 {
 		cfg.Server.WriteTimeout = 30
 	}
+	if cfg.Server.TLS.CipherPolicy == "" {
+		cfg.Server.TLS.CipherPolicy = "intermediate"
+	}
+	if cfg.Server.TLS.ClientAuth.Mode == "" {
+		cfg.Server.TLS.ClientAuth.Mode = "none"
+	}
+	if cfg.Server.TLS.ClientAuth.IdentityBy == "" {
+		cfg.Server.TLS.ClientAuth.IdentityBy = "common_name"
+	}
+	if cfg.Server.TLS.MinVersion == "" {
+		cfg.Server.TLS.MinVersion = "1.2"
+		if cfg.Server.TLS.CipherPolicy == "modern" {
+			cfg.Server.TLS.MinVersion = "1.3"
+		}
+	}
+
+	if len(cfg.CORS.AllowedMethods) == 0 {
+		cfg.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+	}
+	if len(cfg.CORS.AllowedHeaders) == 0 {
+		cfg.CORS.AllowedHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Request-ID", "Idempotency-Key"}
+	}
+	if cfg.CORS.MaxAge == 0 {
+		cfg.CORS.MaxAge = 600
+	}
+
+	if cfg.Tracing.Exporter == "" {
+		cfg.Tracing.Exporter = "stdout"
+	}
+
+	if cfg.Idempotency.Store == "" {
+		cfg.Idempotency.Store = "database"
+	}
+	if cfg.Idempotency.TTL == 0 {
+		cfg.Idempotency.TTL = 86400
+	}
+	if cfg.Idempotency.LockTimeout == 0 {
+		cfg.Idempotency.LockTimeout = 60
+	}
+	if cfg.Idempotency.MaxBodyBytes == 0 {
+		cfg.Idempotency.MaxBodyBytes = 1 << 20
+	}
+
+	if cfg.HTTPCache.MaxEntries == 0 {
+		cfg.HTTPCache.MaxEntries = 10000
+	}
 
 	if cfg.Database.MaxOpenConns == 0 {
 		cfg.Database.MaxOpenConns = 100
 	}
 	if cfg.Database.MaxIdleConns == 0 {
-		cfg.Database.MaxIdleConns = 10
+		// 不超过 max_open_conns，只配置较小的 max_open_conns 时也能通过校验
+		cfg.Database.MaxIdleConns = min(10, cfg.Database.MaxOpenConns)
 	}
 	if cfg.Database.ConnMaxLifetime == 0 {
 		cfg.Database.ConnMaxLifetime = 3600
@@ -125,6 +327,12 @@ func setDefaults(cfg *Config) {
 	if cfg.Log.Output == "" {
 		cfg.Log.Output = "stdout"
 	}
+	if cfg.Log.Access.Format == "" {
+		cfg.Log.Access.Format = "structured"
+	}
+	if cfg.Log.Body.MaxBytes == 0 {
+		cfg.Log.Body.MaxBytes = 4096
+	}
 }
 
 // GetDSN 获取数据库连接字符串
@@ -141,6 +349,33 @@ func (c *DatabaseConfig) GetDSN() string {
 	}
 }
 
+// String 输出数据库配置，密码已脱敏，避免误写入日志
+func (c DatabaseConfig) String() string {
+	type plain DatabaseConfig
+	if c.Password != "" {
+		c.Password = redactedValue
+	}
+	return fmt.Sprintf("%+v", plain(c))
+}
+
+// String 输出诊断配置，令牌已脱敏，避免误写入日志
+func (c DiagnosticsConfig) String() string {
+	type plain DiagnosticsConfig
+	if c.Token != "" {
+		c.Token = redactedValue
+	}
+	return fmt.Sprintf("%+v", plain(c))
+}
+
+// String 输出JWT配置，密钥已脱敏，避免误写入日志
+func (c JWTConfig) String() string {
+	type plain JWTConfig
+	if c.Secret != "" {
+		c.Secret = redactedValue
+	}
+	return fmt.Sprintf("%+v", plain(c))
+}
+
 // IsDevelopment 判断是否为开发环境
 func (c *AppConfig) IsDevelopment() bool {
 	return c.Env == "development"
@@ -151,7 +386,49 @@ func (c *AppConfig) IsProduction() bool {
 	return c.Env == "production"
 }
 
+// GetExpireTime 获取Token有效期，expire_time 单位为小时
+func (c *JWTConfig) GetExpireTime() time.Duration {
+	return time.Duration(c.ExpireTime) * time.Hour
+}
+
+// GetSampleRatio 获取新trace的采样比例，未配置 tracing.sample_ratio 时为1
+func (c *TracingConfig) GetSampleRatio() float64 {
+	if c.SampleRatio != nil {
+		return *c.SampleRatio
+	}
+	return 1
+}
+
+// FeatureEnabled 判断功能开关是否开启，未配置的开关视为关闭
+func (c *Config) FeatureEnabled(name string) bool {
+	return c.Features[name]
+}
+
+// IsDiagnosticsEnabled 是否开启诊断端点，开发环境以外需显式开启 diagnostics.enabled
+func (c *Config) IsDiagnosticsEnabled() bool {
+	if c.Diagnostics.Enabled != nil {
+		return *c.Diagnostics.Enabled
+	}
+	return c.App.IsDevelopment()
+}
+
+// GetTTL 获取幂等键记录的保留时间
+func (c *IdempotencyConfig) GetTTL() time.Duration {
+	return time.Duration(c.TTL) * time.Second
+}
+
+// GetLockTimeout 获取处理中记录的占用时间
+func (c *IdempotencyConfig) GetLockTimeout() time.Duration {
+	return time.Duration(c.LockTimeout) * time.Second
+}
+
 // GetAddress 获取服务器监听地址
 func (c *ServerConfig) GetAddress() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
+
+// IsAdminEnabled 是否单独启动运维服务器
+// 启用后健康检查等运维路由只注册在运维服务器上，公开端口不可访问
+func (c *ServerConfig) IsAdminEnabled() bool {
+	return c.AdminAddress != ""
+}
@@ -0,0 +1,333 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// 配置项来源，文件来源直接使用文件路径
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// LocalProfile 本地覆盖文件的profile名，该文件不应提交到版本库
+const LocalProfile = "local"
+
+// EnvPrefix 环境变量前缀，格式为 APP_分组_字段
+const EnvPrefix = "APP"
+
+// redactedValue 敏感配置脱敏后的占位符
+const redactedValue = "******"
+
+// LoadOptions 配置加载选项
+type LoadOptions struct {
+	Env       string            // 指定环境，优先级高于 APP_APP_ENV 和 config.yaml 中的 app.env
+	Overrides map[string]string // 命令行覆盖，键为YAML路径，如 server.port
+}
+
+// Setting 生效配置中的单个配置项
+type Setting struct {
+	Key    string
+	Value  interface{}
+	Source string
+}
+
+// loadState 最近一次成功加载的配置来源信息
+type loadState struct {
+	path       string
+	opts       LoadOptions
+	env        string
+	files      []string          // 参与合并的配置文件（含不存在的可选文件，用于热更新监听）
+	sources    map[string]string // YAML路径 -> 来源
+	secretKeys map[string]bool   // 值由密钥引用解析得到的配置项，输出时脱敏
+}
+
+// layers 按优先级合并的扁平配置，键为YAML路径
+type layers struct {
+	values     map[string]interface{}
+	sources    map[string]string
+	secretKeys map[string]bool
+}
+
+// set 写入配置值，后写入的来源覆盖先写入的来源
+func (l *layers) set(key string, value interface{}, source string) {
+	l.values[key] = value
+	l.sources[key] = source
+}
+
+// merge 合并一层配置
+func (l *layers) merge(values map[string]interface{}, source string) {
+	for key, value := range values {
+		l.set(key, value, source)
+	}
+}
+
+// loadLayers 按优先级从低到高合并配置：
+// config.yaml < config.{env}.yaml < config.local.yaml < 环境变量 < 命令行覆盖
+// 未出现在任何一层的配置项由 setDefaults 填充默认值，合并完成后统一解析密钥引用
+func loadLayers(configPath string, opts LoadOptions) (*layers, *loadState, error) {
+	base, err := readConfigFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	l := &layers{
+		values:     make(map[string]interface{}),
+		sources:    make(map[string]string),
+		secretKeys: make(map[string]bool),
+	}
+	l.merge(base, configPath)
+
+	env, envSource := resolveEnv(opts, base, configPath)
+	state := &loadState{path: configPath, opts: opts, env: env, files: []string{configPath}}
+
+	for _, profile := range []string{env, LocalProfile} {
+		path := ProfilePath(configPath, profile)
+		state.files = append(state.files, path)
+
+		values, err := readConfigFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+		}
+		l.merge(values, path)
+	}
+
+	for _, key := range envKeys(l.values) {
+		if value, ok := os.LookupEnv(envName(key)); ok {
+			l.set(key, value, SourceEnv+":"+envName(key))
+		}
+		value, source, ok, err := lookupFileEnv(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			l.set(key, value, source)
+			l.secretKeys[key] = true
+		}
+	}
+
+	for key, value := range opts.Overrides {
+		l.set(strings.ToLower(key), value, SourceFlag)
+	}
+
+	// 环境在读取profile前已确定，不允许被profile文件改写
+	l.set("app.env", env, envSource)
+
+	if err := l.resolveSecrets(); err != nil {
+		return nil, nil, err
+	}
+
+	state.sources = l.sources
+	state.secretKeys = l.secretKeys
+	return l, state, nil
+}
+
+// ProfilePath 返回指定profile的配置文件路径，如 configs/config.yaml -> configs/config.production.yaml
+func ProfilePath(configPath, profile string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + profile + ext
+}
+
+// resolveEnv 确定当前环境：命令行 > APP_APP_ENV > config.yaml > development
+func resolveEnv(opts LoadOptions, base map[string]interface{}, configPath string) (string, string) {
+	if opts.Env != "" {
+		return opts.Env, SourceFlag
+	}
+	if env := os.Getenv(envName("app.env")); env != "" {
+		return env, SourceEnv + ":" + envName("app.env")
+	}
+	if env, ok := base["app.env"].(string); ok && env != "" {
+		return env, configPath
+	}
+	return "development", SourceDefault
+}
+
+// readConfigFile 读取单个配置文件并展开为扁平键值，文件不存在时返回 os.ErrNotExist
+func readConfigFile(path string) (map[string]interface{}, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	flattenMap("", v.AllSettings(), values)
+	return values, nil
+}
+
+// flattenMap 将嵌套map展开为以点分隔的键
+func flattenMap(prefix string, m map[string]interface{}, out map[string]interface{}) {
+	for key, value := range m {
+		path := joinKey(prefix, key)
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenMap(path, nested, out)
+			continue
+		}
+		out[path] = value
+	}
+}
+
+// envKeys 返回可通过环境变量覆盖的键：Config结构体中的所有字段以及配置文件中出现的键（如功能开关）
+func envKeys(fileValues map[string]interface{}) []string {
+	seen := make(map[string]struct{})
+	for _, key := range structKeys("", reflect.TypeOf(Config{})) {
+		seen[key] = struct{}{}
+	}
+	for key := range fileValues {
+		seen[key] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// structKeys 按mapstructure标签列出结构体的叶子字段路径，map类型字段的键不在此列出
+func structKeys(prefix string, t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := tagName(field)
+		if name == "" {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			keys = append(keys, structKeys(joinKey(prefix, name), ft)...)
+		case reflect.Map:
+		default:
+			keys = append(keys, joinKey(prefix, name))
+		}
+	}
+	return keys
+}
+
+// envName 返回配置项对应的环境变量名，如 server.port -> APP_SERVER_PORT
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Effective 返回当前生效配置的所有配置项及其来源（按键排序），敏感值已脱敏
+func Effective() []Setting {
+	cfg := globalConfig.Load()
+	state := loaded.Load()
+	if cfg == nil || state == nil {
+		return nil
+	}
+
+	var settings []Setting
+	collectSettings("", reflect.ValueOf(*cfg), func(key string, value interface{}) {
+		source, ok := state.sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+		if (isSecretKey(key) || state.secretKeys[key]) && !reflect.ValueOf(value).IsZero() {
+			value = redactedValue
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: source})
+	})
+
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+// PrintEffective 输出当前生效配置，每行格式为 "键 = 值  # 来源"
+func PrintEffective(w io.Writer) error {
+	state := loaded.Load()
+	if state == nil {
+		return fmt.Errorf("配置尚未加载")
+	}
+
+	if _, err := fmt.Fprintf(w, "# env: %s\n", state.env); err != nil {
+		return err
+	}
+	for _, s := range Effective() {
+		if _, err := fmt.Fprintf(w, "%s = %v  # %s\n", s.Key, s.Value, s.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectSettings 遍历配置结构体的叶子字段，nil指针分组整体跳过
+func collectSettings(prefix string, v reflect.Value, fn func(key string, value interface{})) {
+	for i := 0; i < v.NumField(); i++ {
+		name := tagName(v.Type().Field(i))
+		if name == "" {
+			continue
+		}
+
+		key := joinKey(prefix, name)
+		field := v.Field(i)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+
+		switch field.Kind() {
+		case reflect.Struct:
+			collectSettings(key, field, fn)
+		case reflect.Map:
+			iter := field.MapRange()
+			for iter.Next() {
+				fn(joinKey(key, fmt.Sprint(iter.Key().Interface())), iter.Value().Interface())
+			}
+		default:
+			fn(key, field.Interface())
+		}
+	}
+}
+
+// isSecretKey 判断配置项是否为敏感信息
+func isSecretKey(key string) bool {
+	name := key[strings.LastIndex(key, ".")+1:]
+	return strings.Contains(name, "password") || strings.Contains(name, "secret") ||
+		strings.Contains(name, "token") || strings.HasSuffix(name, "_key")
+}
+
+// tagName 返回字段的mapstructure名称，未声明或忽略的字段返回空字符串
+func tagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("mapstructure"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// joinKey 拼接YAML路径
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretResolveTimeout 加载配置时解析所有密钥引用的总超时
+const secretResolveTimeout = 10 * time.Second
+
+// fileEnvSuffix 以文件提供配置值的环境变量后缀，如 APP_DATABASE_PASSWORD_FILE
+const fileEnvSuffix = "_FILE"
+
+// SecretResolver 解析 scheme://ref 形式的密钥引用
+// 实现不应在错误信息中包含密钥内容
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc 函数形式的 SecretResolver
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve 实现 SecretResolver 接口
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// MapResolver 从内存映射读取密钥，用于本地开发和测试时替代vault等外部服务
+type MapResolver map[string]string
+
+// Resolve 实现 SecretResolver 接口
+func (m MapResolver) Resolve(_ context.Context, ref string) (string, error) {
+	value, ok := m[ref]
+	if !ok {
+		return "", fmt.Errorf("密钥 %q 不存在", ref)
+	}
+	return value, nil
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]SecretResolver{
+		"file": SecretResolverFunc(resolveFileRef),
+		"env":  SecretResolverFunc(resolveEnvRef),
+	}
+
+	envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// RegisterSecretResolver 注册密钥引用解析器，需在 Load 之前调用
+// 配置值以 scheme:// 开头时交给对应解析器，未注册的scheme（如 http://）按普通字符串处理
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[strings.ToLower(scheme)] = resolver
+}
+
+// resolveFileRef 读取文件内容作为密钥，去除末尾换行
+func resolveFileRef(_ context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveEnvRef 读取环境变量作为密钥
+func resolveEnvRef(_ context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("环境变量 %s 未设置", ref)
+	}
+	return value, nil
+}
+
+// lookupResolver 查找值对应的解析器，返回解析器和去掉scheme的引用
+func lookupResolver(value string) (SecretResolver, string, bool) {
+	scheme, ref, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil, "", false
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	resolver, ok := resolvers[strings.ToLower(scheme)]
+	return resolver, ref, ok
+}
+
+// resolveSecrets 解析所有字符串配置值中的密钥引用，解析出的键记录在 secretKeys 中
+// 错误信息只包含配置路径和引用，不包含解析结果
+func (l *layers) resolveSecrets() error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretResolveTimeout)
+	defer cancel()
+
+	keys := make([]string, 0, len(l.values))
+	for key := range l.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := &ValidationError{}
+	for _, key := range keys {
+		value, ok := l.values[key].(string)
+		if !ok {
+			continue
+		}
+
+		if resolver, ref, ok := lookupResolver(value); ok {
+			secret, err := resolver.Resolve(ctx, ref)
+			if err != nil {
+				result.add(key, "解析密钥引用 %s 失败: %v", value, err)
+				continue
+			}
+			l.values[key] = secret
+			l.sources[key] += " -> " + value
+			l.secretKeys[key] = true
+			continue
+		}
+
+		if envRefPattern.MatchString(value) {
+			var missing []string
+			expanded := envRefPattern.ReplaceAllStringFunc(value, func(m string) string {
+				name := envRefPattern.FindStringSubmatch(m)[1]
+				v, ok := os.LookupEnv(name)
+				if !ok {
+					missing = append(missing, name)
+				}
+				return v
+			})
+			if len(missing) > 0 {
+				result.add(key, "环境变量 %s 未设置", strings.Join(missing, ", "))
+				continue
+			}
+			l.values[key] = expanded
+			l.sources[key] += " -> " + value
+			l.secretKeys[key] = true
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		return result
+	}
+	return nil
+}
+
+// lookupFileEnv 读取 APP_XXX_FILE 指向的文件作为配置值（Docker/Kubernetes secrets 约定）
+func lookupFileEnv(key string) (value, source string, ok bool, err error) {
+	name := envName(key) + fileEnvSuffix
+	path, ok := os.LookupEnv(name)
+	if !ok {
+		return "", "", false, nil
+	}
+	if _, conflict := os.LookupEnv(envName(key)); conflict {
+		return "", "", false, fmt.Errorf("%s 与 %s 不能同时设置", envName(key), name)
+	}
+
+	value, err = resolveFileRef(context.Background(), path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("读取 %s 指向的文件失败: %w", name, err)
+	}
+	return value, SourceEnv + ":" + name, true, nil
+}
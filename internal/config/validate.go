@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// MinProductionSecretLength 生产环境JWT密钥最小长度
+const MinProductionSecretLength = 32
+
+// FieldError 单个配置项的校验错误
+type FieldError struct {
+	Path    string // YAML路径，如 database.driver
+	Message string
+}
+
+// ValidationError 配置校验错误，汇总所有不合法的配置项
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error 实现 error 接口，每个问题占一行
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "配置校验失败（共%d项）:", len(e.Errors))
+	for _, fe := range e.Errors {
+		fmt.Fprintf(&b, "\n  - %s: %s", fe.Path, fe.Message)
+	}
+	return b.String()
+}
+
+// add 添加一项校验错误
+func (e *ValidationError) add(path, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate 校验配置，一次性返回所有问题
+// 字段规则由结构体的 validate 标签声明，跨分组规则在 validateRules 中定义
+func Validate(cfg *Config) error {
+	result := &ValidationError{}
+
+	if err := newValidator().Struct(cfg); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return err
+		}
+		for _, fe := range fieldErrs {
+			result.add(yamlPath(fe.Namespace()), "%s", describe(fe))
+		}
+	}
+
+	validateRules(cfg, result)
+
+	if len(result.Errors) > 0 {
+		return result
+	}
+	return nil
+}
+
+// validateRules 无法用标签表达的规则
+func validateRules(cfg *Config, result *ValidationError) {
+	if cfg.App.IsProduction() {
+		switch {
+		case cfg.JWT == nil:
+			result.add("jwt", "生产环境必须配置")
+		case cfg.JWT.Secret == "":
+			// 已由 required 标签报告
+		case len(cfg.JWT.Secret) < MinProductionSecretLength:
+			result.add("jwt.secret", "生产环境密钥长度不能少于%d个字符，当前%d个", MinProductionSecretLength, len(cfg.JWT.Secret))
+		}
+	}
+	if samePort(cfg.Server.AdminAddress, cfg.Server.Port) {
+		result.add("server.admin_address", "端口不能与 server.port 相同，当前值 %q", cfg.Server.AdminAddress)
+	}
+
+	tlsCfg := cfg.Server.TLS
+	if cfg.Server.H2C && tlsCfg.Enabled {
+		result.add("server.h2c", "启用TLS时HTTP/2自动开启，h2c只用于明文部署")
+	}
+	if tlsCfg.CipherPolicy == "modern" && tlsCfg.MinVersion == "1.2" {
+		result.add("server.tls.min_version", "cipher_policy 为 modern 时只支持TLS1.3，当前值 %q", tlsCfg.MinVersion)
+	}
+	if tlsCfg.RedirectAddress != "" {
+		if !tlsCfg.Enabled {
+			result.add("server.tls.redirect_address", "需要同时启用 server.tls.enabled")
+		}
+		if samePort(tlsCfg.RedirectAddress, cfg.Server.Port) {
+			result.add("server.tls.redirect_address", "端口不能与 server.port 相同，当前值 %q", tlsCfg.RedirectAddress)
+		}
+	}
+	for i, origin := range cfg.CORS.AllowedOrigins {
+		path := fmt.Sprintf("cors.allowed_origins[%d]", i)
+		if origin == "*" {
+			if cfg.CORS.AllowCredentials {
+				result.add(path, "allow_credentials 为 true 时不能使用 \"*\"，请列出具体来源")
+			}
+			continue
+		}
+		if err := validateOrigin(origin); err != nil {
+			result.add(path, "%s，当前值 %q", err.Error(), origin)
+		}
+	}
+
+	if tlsCfg.ClientAuth.IsEnabled() {
+		if !tlsCfg.Enabled {
+			result.add("server.tls.client_auth.mode", "客户端证书认证需要同时启用 server.tls.enabled")
+		}
+		if tlsCfg.ClientAuth.CAFile == "" {
+			result.add("server.tls.client_auth.ca_file", "mode 为 %q 时必填", tlsCfg.ClientAuth.Mode)
+		}
+	}
+}
+
+// validateOrigin 校验跨域来源格式：scheme://host[:port]，通配符只能作为最左侧的完整标签
+func validateOrigin(origin string) error {
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return fmt.Errorf("必须以 http:// 或 https:// 开头")
+	}
+	if host == "" || strings.ContainsAny(host, "/?#") {
+		return fmt.Errorf("只能包含协议、主机和端口，不能有路径")
+	}
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") {
+		return fmt.Errorf("通配符只能写成 *.example.com 的形式")
+	}
+	return nil
+}
+
+// samePort 判断 host:port 格式的地址是否与端口相同
+func samePort(address string, port int) bool {
+	if address == "" {
+		return false
+	}
+	_, p, err := net.SplitHostPort(address)
+	return err == nil && p == strconv.Itoa(port)
+}
+
+// newValidator 创建使用 mapstructure 标签作为字段名的校验器
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// yamlPath 将校验器命名空间转换为YAML路径（去掉根结构体名）
+func yamlPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// describe 将校验失败转换为可读描述
+func describe(fe validator.FieldError) string {
+	value := fe.Value()
+	switch fe.Tag() {
+	case "required":
+		return "必填"
+	case "required_if":
+		parts := strings.Fields(fe.Param())
+		if len(parts) == 2 {
+			return fmt.Sprintf("当 %s 为 %q 时必填", toSnake(parts[0]), parts[1])
+		}
+		return "必填"
+	case "oneof":
+		return fmt.Sprintf("必须是 [%s] 之一，当前值 %q", strings.ReplaceAll(fe.Param(), " ", ", "), value)
+	case "min", "gte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("长度不能少于%s个字符", fe.Param())
+		}
+		return fmt.Sprintf("不能小于%s，当前值 %v", fe.Param(), value)
+	case "gt":
+		return fmt.Sprintf("必须大于%s，当前值 %v", fe.Param(), value)
+	case "max", "lte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("长度不能超过%s个字符", fe.Param())
+		}
+		return fmt.Sprintf("不能大于%s，当前值 %v", fe.Param(), value)
+	case "hostname_port":
+		return fmt.Sprintf("必须是 host:port 格式，如 127.0.0.1:9090，当前值 %q", value)
+	case "ltefield":
+		return fmt.Sprintf("不能大于 %s，当前值 %v", toSnake(fe.Param()), value)
+	default:
+		return fmt.Sprintf("不满足规则 %s，当前值 %v", fe.Tag(), value)
+	}
+}
+
+// toSnake 将字段名转换为snake_case，与mapstructure标签保持一致
+func toSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestValidate_Aggregation 所有不合法的配置项一次性汇总返回，同一项不重复报告
+func TestValidate_Aggregation(t *testing.T) {
+	base, err := os.ReadFile(filepath.Join("..", "..", "configs", "config.yaml"))
+	if err != nil {
+		t.Fatalf("读取示例配置失败: %v", err)
+	}
+	strongSecret := strings.Repeat("s", MinProductionSecretLength)
+
+	tests := []struct {
+		name string
+		env  map[string]string // 通过环境变量覆盖的配置项
+		jwt  string            // 追加的jwt分组，为空时不配置
+		want []string
+	}{
+		{
+			name: "开发环境默认配置",
+		},
+		{
+			name: "生产环境缺少jwt分组",
+			env:  map[string]string{"APP_APP_ENV": "production"},
+			want: []string{"jwt"},
+		},
+		{
+			name: "生产环境密钥为空只报告一次",
+			env:  map[string]string{"APP_APP_ENV": "production"},
+			jwt:  `{secret: "", expire_time: 1}`,
+			want: []string{"jwt.secret"},
+		},
+		{
+			name: "生产环境密钥过短",
+			env:  map[string]string{"APP_APP_ENV": "production"},
+			jwt:  `{secret: "short", expire_time: 1}`,
+			want: []string{"jwt.secret"},
+		},
+		{
+			name: "生产环境密钥合法",
+			env:  map[string]string{"APP_APP_ENV": "production"},
+			jwt:  `{secret: "` + strongSecret + `", expire_time: 1}`,
+		},
+		{
+			name: "多个分组的问题一并返回",
+			env: map[string]string{
+				"APP_LOG_LEVEL":               "verbose",
+				"APP_DATABASE_MAX_IDLE_CONNS": "1000",
+				"APP_SERVER_ADMIN_ADDRESS":    "127.0.0.1:8080",
+			},
+			jwt:  `{secret: "", expire_time: 0}`,
+			want: []string{"database.max_idle_conns", "jwt.expire_time", "jwt.secret", "log.level", "server.admin_address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			content := string(base)
+			if tt.jwt != "" {
+				content += "\njwt: " + tt.jwt + "\n"
+			}
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("写入配置失败: %v", err)
+			}
+
+			_, err := Load(path)
+
+			var got []string
+			if err != nil {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("期望 *ValidationError，实际: %v", err)
+				}
+				for _, fe := range verr.Errors {
+					got = append(got, fe.Path)
+				}
+				sort.Strings(got)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("校验错误 = %v, 期望 %v\n%v", got, tt.want, err)
+			}
+		})
+	}
+}
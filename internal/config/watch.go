@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ChangeHandler 配置变更回调，old 为变更前的配置，new 为已生效的新配置
+type ChangeHandler func(old, new *Config)
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   []ChangeHandler
+	watchOnce     sync.Once
+	// reloadMu 串行化 Reload，各配置文件的监听协程可能同时触发
+	reloadMu sync.Mutex
+)
+
+// RestartRequiredError 配置中需要重启才能生效的字段发生了变化
+// 这些字段保持原值，其余变更照常生效
+type RestartRequiredError struct {
+	Paths []string
+}
+
+// Error 实现 error 接口
+func (e *RestartRequiredError) Error() string {
+	return fmt.Sprintf("以下配置需要重启后生效，本次未应用: %s", strings.Join(e.Paths, ", "))
+}
+
+// Subscribe 订阅配置变更，仅在热更新成功后调用
+// 回调在监听协程中按变更顺序同步执行，耗时操作应自行异步处理，回调中不能调用 Reload
+func Subscribe(fn ChangeHandler) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Watch 监听参与合并的配置文件（含尚未创建的profile文件），变化时重新加载、校验并原子替换全局配置
+// 校验失败时保留原配置；onError 接收加载失败和 *RestartRequiredError 等问题（可为nil）
+func Watch(onError func(err error)) error {
+	state := loaded.Load()
+	if state == nil {
+		return fmt.Errorf("配置尚未加载")
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+
+	watchOnce.Do(func() {
+		for _, path := range state.files {
+			v := viper.New()
+			v.SetConfigFile(path)
+			v.OnConfigChange(func(e fsnotify.Event) {
+				if e.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					return
+				}
+				if err := Reload(); err != nil {
+					onError(err)
+				}
+			})
+			v.WatchConfig()
+		}
+	})
+	return nil
+}
+
+// Reload 按首次加载时的环境和选项重新加载配置并通知订阅者
+// 需要重启的字段发生变化时保留原值并返回 *RestartRequiredError，其余变更仍会生效
+// 加载、比较、替换和通知在同一把锁内完成，并发调用按顺序生效
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	state := loaded.Load()
+	if state == nil {
+		return fmt.Errorf("配置尚未加载")
+	}
+
+	opts := state.opts
+	opts.Env = state.env
+	next, nextState, err := load(state.path, opts)
+	if err != nil {
+		return fmt.Errorf("重新加载配置失败，继续使用原配置: %w", err)
+	}
+	nextState.opts = state.opts
+	nextState.sources["app.env"] = state.sources["app.env"]
+
+	prev := globalConfig.Load()
+	var restartErr error
+	if prev != nil {
+		if paths := keepRestartFields(prev, next); len(paths) > 0 {
+			restartErr = &RestartRequiredError{Paths: paths}
+			keepRestartSources(state, nextState, paths)
+		}
+	}
+	loaded.Store(nextState)
+	if prev != nil && reflect.DeepEqual(prev, next) {
+		return restartErr
+	}
+
+	globalConfig.Store(next)
+	notify(prev, next)
+	return restartErr
+}
+
+// keepRestartSources 未生效的字段沿用原来的来源，Effective 显示的来源与实际生效的值一致
+func keepRestartSources(old, new *loadState, paths []string) {
+	restored := func(key string) bool {
+		for _, path := range paths {
+			if key == path || strings.HasPrefix(key, path+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for key := range new.sources {
+		if restored(key) {
+			delete(new.sources, key)
+		}
+	}
+	for key, source := range old.sources {
+		if restored(key) {
+			new.sources[key] = source
+		}
+	}
+	for key := range new.secretKeys {
+		if restored(key) {
+			delete(new.secretKeys, key)
+		}
+	}
+	for key := range old.secretKeys {
+		if restored(key) {
+			new.secretKeys[key] = true
+		}
+	}
+}
+
+// notify 通知所有订阅者
+func notify(old, new *Config) {
+	subscribersMu.RLock()
+	handlers := make([]ChangeHandler, len(subscribers))
+	copy(handlers, subscribers)
+	subscribersMu.RUnlock()
+
+	for _, fn := range handlers {
+		fn(old, new)
+	}
+}
+
+// keepRestartFields 将需要重启才能生效的字段恢复为原值，返回发生变化的字段路径
+func keepRestartFields(old, new *Config) []string {
+	var paths []string
+	keep := func(path string, oldValue, newValue interface{}, restore func()) {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			paths = append(paths, path)
+			restore()
+		}
+	}
+
+	keep("app.name", old.App.Name, new.App.Name, func() { new.App.Name = old.App.Name })
+	keep("app.env", old.App.Env, new.App.Env, func() { new.App.Env = old.App.Env })
+	keep("app.node_id", old.App.NodeID, new.App.NodeID, func() { new.App.NodeID = old.App.NodeID })
+	keep("app.hot_reload", old.App.HotReload, new.App.HotReload, func() { new.App.HotReload = old.App.HotReload })
+	if changed := changedPaths("server", old.Server, new.Server); len(changed) > 0 {
+		paths = append(paths, changed...)
+		new.Server = old.Server
+	}
+	if changed := changedPaths("database", old.Database, new.Database); len(changed) > 0 {
+		paths = append(paths, changed...)
+		new.Database = old.Database
+	}
+	if changed := changedPaths("tracing", old.Tracing, new.Tracing); len(changed) > 0 {
+		paths = append(paths, changed...)
+		new.Tracing = old.Tracing
+	}
+	if changed := changedPaths("diagnostics", old.Diagnostics, new.Diagnostics); len(changed) > 0 {
+		paths = append(paths, changed...)
+		new.Diagnostics = old.Diagnostics
+	}
+	keep("idempotency.store", old.Idempotency.Store, new.Idempotency.Store, func() { new.Idempotency.Store = old.Idempotency.Store })
+	keep("http_cache.max_entries", old.HTTPCache.MaxEntries, new.HTTPCache.MaxEntries, func() { new.HTTPCache.MaxEntries = old.HTTPCache.MaxEntries })
+	keep("log.format", old.Log.Format, new.Log.Format, func() { new.Log.Format = old.Log.Format })
+	keep("log.output", old.Log.Output, new.Log.Output, func() { new.Log.Output = old.Log.Output })
+	keep("log.file_path", old.Log.FilePath, new.Log.FilePath, func() { new.Log.FilePath = old.Log.FilePath })
+
+	return paths
+}
+
+// changedPaths 比较两个同类型配置分组，返回值不同的字段路径
+func changedPaths(prefix string, old, new interface{}) []string {
+	var paths []string
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	for i := 0; i < ov.NumField(); i++ {
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			name := strings.SplitN(ov.Type().Field(i).Tag.Get("mapstructure"), ",", 2)[0]
+			paths = append(paths, prefix+"."+name)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,300 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// 变更类型
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionPatch     = "patch"
+)
+
+// Change 生成或修改的文件
+type Change struct {
+	Path   string
+	Action string
+}
+
+// Generator 业务模块代码生成器
+type Generator struct {
+	Root   string           // 项目根目录（go.mod所在目录）
+	Force  bool             // 覆盖已存在的生成文件
+	DryRun bool             // 只返回变更计划，不写入文件
+	Now    func() time.Time // 迁移ID使用的时间，为nil时使用当前时间
+}
+
+// moduleData 模板数据
+type moduleData struct {
+	Name        string // PascalCase，如 OrderItem
+	Var         string // camelCase，如 orderItem
+	PluralVar   string // camelCase复数，如 orderItems
+	Snake       string // snake_case，如 order_item
+	Table       string
+	Route       string
+	Comment     string
+	Public      bool
+	MigrationID string
+	HasRequired bool
+	Fields      []fieldData
+	Filters     []fieldData
+}
+
+// fieldData 字段模板数据
+type fieldData struct {
+	Name          string
+	Column        string
+	GoType        string
+	PtrType       string // 更新请求和查询条件使用的指针类型
+	Comment       string
+	GormTag       string
+	CreateBinding string
+	UpdateBinding string
+	DocType       string
+	DocExample    string
+	Required      bool
+	Hidden        bool
+	IsString      bool
+}
+
+// Generate 生成模块文件并注册到Wire和模块列表
+// 所有变更在内存中准备完成后才写入磁盘，任一步骤失败时不修改任何文件
+func (g *Generator) Generate(spec *ModuleSpec) ([]Change, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(g.Root, "go.mod")); err != nil {
+		return nil, fmt.Errorf("%s 不是项目根目录（未找到go.mod）", g.Root)
+	}
+
+	data, err := newModuleData(spec)
+	if err != nil {
+		return nil, err
+	}
+	data.MigrationID = g.now().Format("20060102150405") + "_create_" + data.Table
+
+	files := make(map[string][]byte)
+	var changes []Change
+
+	generated := []struct{ template, path string }{
+		{"model.go.tmpl", "internal/model/" + data.Snake + ".go"},
+		{"repository.go.tmpl", "internal/repository/" + data.Snake + "_repository.go"},
+		{"service.go.tmpl", "internal/service/" + data.Snake + "_service.go"},
+		{"handler.go.tmpl", "internal/handler/" + data.Snake + "_handler.go"},
+		{"handler_test.go.tmpl", "internal/handler/" + data.Snake + "_handler_test.go"},
+		{"module.go.tmpl", "internal/modules/" + data.Snake + ".go"},
+	}
+	for _, gen := range generated {
+		action := ActionCreate
+		if _, err := os.Stat(filepath.Join(g.Root, gen.path)); err == nil {
+			if !g.Force {
+				return nil, fmt.Errorf("文件 %s 已存在，使用 --force 覆盖", gen.path)
+			}
+			action = ActionOverwrite
+		}
+
+		content, err := render(gen.template, data)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = formatGo(gen.path, content); err != nil {
+			return nil, err
+		}
+		files[gen.path] = content
+		changes = append(changes, Change{Path: gen.path, Action: action})
+	}
+
+	patched, err := g.patchAll(data)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(patched))
+	for path := range patched {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		files[path] = patched[path]
+		changes = append(changes, Change{Path: path, Action: ActionPatch})
+	}
+
+	if g.DryRun {
+		return changes, nil
+	}
+	for _, change := range changes {
+		if err := os.WriteFile(filepath.Join(g.Root, change.Path), files[change.Path], 0o644); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", change.Path, err)
+		}
+	}
+	return changes, nil
+}
+
+// patchAll 修补已有文件，返回内容发生变化的文件
+func (g *Generator) patchAll(data *moduleData) (map[string][]byte, error) {
+	patches := []struct {
+		path  string
+		patch func(src string) (string, error)
+	}{
+		{providersFile, func(src string) (string, error) { return patchProviders(src, data) }},
+		{wireFile, func(src string) (string, error) { return patchProviderSet(src, data) }},
+		{wireGenFile, func(src string) (string, error) { return patchWireGen(src, data) }},
+		{modulesFile, func(src string) (string, error) { return patchModules(src, data) }},
+		{apiDocFile, func(src string) (string, error) { return patchAPIDoc(src, data) }},
+	}
+
+	result := make(map[string][]byte)
+	for _, p := range patches {
+		original, err := os.ReadFile(filepath.Join(g.Root, p.path))
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %w", p.path, err)
+		}
+
+		src, err := p.patch(string(original))
+		if err != nil {
+			return nil, fmt.Errorf("修改 %s 失败: %w", p.path, err)
+		}
+		if src == string(original) {
+			continue
+		}
+
+		content := []byte(src)
+		if strings.HasSuffix(p.path, ".go") {
+			if content, err = formatGo(p.path, content); err != nil {
+				return nil, err
+			}
+		}
+		result[p.path] = content
+	}
+	return result, nil
+}
+
+// now 返回当前时间
+func (g *Generator) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+// newModuleData 根据模块定义构建模板数据
+func newModuleData(spec *ModuleSpec) (*moduleData, error) {
+	snake := toSnake(spec.Name)
+	data := &moduleData{
+		Name:      toPascal(snake),
+		Var:       toCamel(snake),
+		PluralVar: toCamel(pluralize(snake)),
+		Snake:     snake,
+		Table:     spec.Table,
+		Route:     spec.Route,
+		Comment:   spec.Comment,
+		Public:    spec.Public,
+	}
+	if token.IsKeyword(data.Var) || token.IsKeyword(data.PluralVar) {
+		return nil, fmt.Errorf("模块名 %s 与Go关键字冲突", spec.Name)
+	}
+
+	for _, f := range spec.Fields {
+		fd := newFieldData(f)
+		data.Fields = append(data.Fields, fd)
+		if f.Filter {
+			data.Filters = append(data.Filters, fd)
+		}
+		if fd.CreateBinding != "" && strings.Contains(fd.CreateBinding, "required") {
+			data.HasRequired = true
+		}
+	}
+	return data, nil
+}
+
+// newFieldData 根据字段定义构建模板数据
+func newFieldData(f FieldSpec) fieldData {
+	ft := fieldTypes[f.Type]
+	goType := ft.goType
+	// 可选的时间字段使用指针，避免写入零值时间
+	if f.Type == "time" && !f.Required {
+		goType = "*" + goType
+	}
+	fd := fieldData{
+		Name:     toPascal(f.Name),
+		Column:   f.Name,
+		GoType:   goType,
+		PtrType:  "*" + strings.TrimPrefix(goType, "*"),
+		Comment:  f.Comment,
+		Required: f.Required,
+		Hidden:   f.Hidden,
+		IsString: ft.goType == "string",
+	}
+
+	var gormTag, create, update []string
+	if dbType := ft.dbType(f.Size); dbType != "" {
+		gormTag = append(gormTag, "type:"+dbType)
+	}
+	if f.Required {
+		gormTag = append(gormTag, "not null")
+		// bool 的零值 false 无法通过 required 校验
+		if f.Type != "bool" {
+			create = append(create, "required")
+		}
+	}
+	if f.Unique {
+		gormTag = append(gormTag, "uniqueIndex")
+	} else if f.Index {
+		gormTag = append(gormTag, "index")
+	}
+	if f.Type == "string" {
+		create = append(create, fmt.Sprintf("max=%d", f.Size))
+		update = append(update, "omitempty", fmt.Sprintf("max=%d", f.Size))
+	}
+
+	fd.GormTag = strings.Join(gormTag, ";")
+	fd.CreateBinding = strings.Join(create, ",")
+	fd.UpdateBinding = strings.Join(update, ",")
+
+	switch f.Type {
+	case "string", "text":
+		fd.DocType, fd.DocExample = "string", fmt.Sprintf("%q", f.Comment)
+	case "time":
+		fd.DocType, fd.DocExample = "string", `"2024-01-01T12:00:00Z"`
+	case "float64":
+		fd.DocType, fd.DocExample = "number", "0"
+	case "bool":
+		fd.DocType, fd.DocExample = "bool", "false"
+	default:
+		fd.DocType, fd.DocExample = "int", "0"
+	}
+	return fd
+}
+
+// render 渲染模板
+func render(name string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, fmt.Errorf("渲染模板 %s 失败: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatGo 格式化Go代码，语法错误时返回错误
+func formatGo(path string, src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("格式化 %s 失败: %w", path, err)
+	}
+	return formatted, nil
+}
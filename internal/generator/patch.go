@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// 需要修补的文件
+const (
+	providersFile = "internal/wire/providers.go"
+	wireFile      = "internal/wire/wire.go"
+	wireGenFile   = "internal/wire/wire_gen.go"
+	modulesFile   = "internal/modules/modules.go"
+	apiDocFile    = "docs/api.md"
+)
+
+// 插入位置锚点，新内容插入到锚点所在行之前
+const (
+	anchorHandlersDoc  = "// Handlers 包含所有Handler实例"
+	anchorHandlerField = "// 在此添加你的Handler字段"
+	anchorProviderSet  = "\tProvideHandlers,\n"
+	anchorInjectorCall = "\thandlers := ProvideHandlers("
+	anchorModule       = "// 在此添加你的模块"
+	anchorAPIChangelog = "## 更新日志"
+)
+
+var injectorCallPattern = regexp.MustCompile(`(handlers := ProvideHandlers\()([^)]*)(\))`)
+
+// patchProviders 添加Provider函数、Handlers字段并修改 ProvideHandlers
+func patchProviders(src string, data *moduleData) (string, error) {
+	var err error
+	if !strings.Contains(src, "func Provide"+data.Name+"Handler(") {
+		providers, err := render("providers.go.tmpl", data)
+		if err != nil {
+			return "", err
+		}
+		if src, err = insertBeforeLine(src, anchorHandlersDoc, string(providers)); err != nil {
+			return "", err
+		}
+	}
+
+	fieldPattern := regexp.MustCompile(`\n\t` + data.Name + `Handler\s+\*handler\.` + data.Name + `Handler\n`)
+	if !fieldPattern.MatchString(src) {
+		field := fmt.Sprintf("\t%sHandler *handler.%sHandler\n", data.Name, data.Name)
+		if src, err = insertBeforeLine(src, anchorHandlerField, field); err != nil {
+			return "", err
+		}
+	}
+
+	return patchProvideHandlers(src, data)
+}
+
+// patchProvideHandlers 为 ProvideHandlers 添加参数和字段赋值
+// 函数体只能是 return &Handlers{...}，被手动修改过时返回错误
+func patchProvideHandlers(src string, data *moduleData) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+
+	var fn *ast.FuncDecl
+	for _, decl := range file.Decls {
+		if d, ok := decl.(*ast.FuncDecl); ok && d.Recv == nil && d.Name.Name == "ProvideHandlers" {
+			fn = d
+		}
+	}
+	if fn == nil {
+		return "", fmt.Errorf("未找到 ProvideHandlers 函数")
+	}
+
+	text := func(node ast.Node) string {
+		return src[fset.Position(node.Pos()).Offset:fset.Position(node.End()).Offset]
+	}
+
+	handlerType := "*handler." + data.Name + "Handler"
+	var params []string
+	for _, field := range fn.Type.Params.List {
+		typ := text(field.Type)
+		if typ == handlerType {
+			return src, nil
+		}
+		for _, name := range field.Names {
+			params = append(params, name.Name+" "+typ)
+		}
+	}
+
+	lit := handlersLiteral(fn)
+	if lit == nil {
+		return "", fmt.Errorf("ProvideHandlers 已被手动修改，请手动注入 %sHandler", data.Name)
+	}
+	elts := make([]string, 0, len(lit.Elts)+1)
+	for _, elt := range lit.Elts {
+		elts = append(elts, text(elt))
+	}
+
+	param := data.Var + "Handler"
+	params = append(params, param+" "+handlerType)
+	elts = append(elts, data.Name+"Handler: "+param)
+
+	var b strings.Builder
+	b.WriteString("func ProvideHandlers(\n")
+	for _, p := range params {
+		b.WriteString("\t" + p + ",\n")
+	}
+	b.WriteString(") *Handlers {\n\treturn &Handlers{\n")
+	for _, e := range elts {
+		b.WriteString("\t\t" + e + ",\n")
+	}
+	b.WriteString("\t}\n}")
+
+	start, end := fset.Position(fn.Pos()).Offset, fset.Position(fn.End()).Offset
+	return src[:start] + b.String() + src[end:], nil
+}
+
+// handlersLiteral 返回 ProvideHandlers 中 return &Handlers{...} 的复合字面量
+func handlersLiteral(fn *ast.FuncDecl) *ast.CompositeLit {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return nil
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	unary, ok := ret.Results[0].(*ast.UnaryExpr)
+	if !ok || unary.Op != token.AND {
+		return nil
+	}
+	lit, ok := unary.X.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	if ident, ok := lit.Type.(*ast.Ident); !ok || ident.Name != "Handlers" {
+		return nil
+	}
+	return lit
+}
+
+// patchProviderSet 在 ProviderSet 中添加模块的Provider
+func patchProviderSet(src string, data *moduleData) (string, error) {
+	if strings.Contains(src, "\tProvide"+data.Name+"Repository,\n") {
+		return src, nil
+	}
+	lines := fmt.Sprintf("\tProvide%[1]sRepository,\n\tProvide%[1]sService,\n\tProvide%[1]sHandler,\n", data.Name)
+	return insertBeforeLine(src, anchorProviderSet, lines)
+}
+
+// patchWireGen 同步修改Wire生成的代码，与运行wire命令的结果等价
+func patchWireGen(src string, data *moduleData) (string, error) {
+	src, err := patchProviderSet(src, data)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(src, "Provide"+data.Name+"Repository(baseRepository)") {
+		return src, nil
+	}
+
+	lines := fmt.Sprintf("\t%[2]sRepository := Provide%[1]sRepository(baseRepository)\n"+
+		"\t%[2]sService := Provide%[1]sService(%[2]sRepository)\n"+
+		"\t%[2]sHandler := Provide%[1]sHandler(%[2]sService)\n", data.Name, data.Var)
+	if src, err = insertBeforeLine(src, anchorInjectorCall, lines); err != nil {
+		return "", err
+	}
+
+	if !injectorCallPattern.MatchString(src) {
+		return "", fmt.Errorf("未找到 ProvideHandlers 调用")
+	}
+	return injectorCallPattern.ReplaceAllStringFunc(src, func(call string) string {
+		m := injectorCallPattern.FindStringSubmatch(call)
+		args := strings.TrimSpace(m[2])
+		if args != "" {
+			args += ", "
+		}
+		return m[1] + args + data.Var + "Handler" + m[3]
+	}), nil
+}
+
+// patchModules 在模块列表中注册模块
+func patchModules(src string, data *moduleData) (string, error) {
+	if strings.Contains(src, "New"+data.Name+"Module(") {
+		return src, nil
+	}
+	line := fmt.Sprintf("\t\tNew%[1]sModule(handlers.%[1]sHandler),\n", data.Name)
+	return insertBeforeLine(src, anchorModule, line)
+}
+
+// patchAPIDoc 在API文档的更新日志前添加模块接口文档
+func patchAPIDoc(src string, data *moduleData) (string, error) {
+	if strings.Contains(src, "GET /api/v1"+data.Route+"\n") {
+		return src, nil
+	}
+	doc, err := render("api.md.tmpl", data)
+	if err != nil {
+		return "", err
+	}
+	return insertBeforeLine(src, anchorAPIChangelog, string(doc))
+}
+
+// insertBeforeLine 在锚点所在行之前插入文本，锚点必须唯一
+func insertBeforeLine(src, anchor, text string) (string, error) {
+	switch strings.Count(src, anchor) {
+	case 0:
+		return "", fmt.Errorf("未找到插入位置 %q，请确认文件未被手动删除该标记", strings.TrimSpace(anchor))
+	case 1:
+	default:
+		return "", fmt.Errorf("插入位置 %q 不唯一", strings.TrimSpace(anchor))
+	}
+
+	idx := strings.Index(src, anchor)
+	lineStart := strings.LastIndex(src[:idx], "\n") + 1
+	return src[:lineStart] + text + src[lineStart:], nil
+}
@@ -0,0 +1,235 @@
+// Package generator 业务模块代码生成
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultStringSize 字符串字段默认长度
+const defaultStringSize = 255
+
+// ModuleSpec 模块定义，可从命令行参数或YAML文件读取
+type ModuleSpec struct {
+	Name    string      `yaml:"name"`    // 模块名，如 order、order_item、OrderItem
+	Comment string      `yaml:"comment"` // 中文名称，用于注释和提示信息，如 订单
+	Table   string      `yaml:"table"`   // 表名，默认为名称的复数形式
+	Route   string      `yaml:"route"`   // 路由前缀，默认为 /名称复数（短横线分隔）
+	Public  bool        `yaml:"public"`  // 为true时注册为公开路由，默认需要认证
+	Fields  []FieldSpec `yaml:"fields"`
+}
+
+// FieldSpec 字段定义
+type FieldSpec struct {
+	Name     string `yaml:"name"`     // 字段名，snake_case
+	Type     string `yaml:"type"`     // 字段类型，见 fieldTypes
+	Comment  string `yaml:"comment"`  // 字段说明
+	Size     int    `yaml:"size"`     // 字符串长度，默认255
+	Required bool   `yaml:"required"` // 创建时必填
+	Unique   bool   `yaml:"unique"`   // 唯一索引
+	Index    bool   `yaml:"index"`    // 普通索引
+	Filter   bool   `yaml:"filter"`   // 作为列表查询条件
+	Hidden   bool   `yaml:"hidden"`   // 不在响应中返回（如密码）
+}
+
+// fieldType 字段类型映射
+type fieldType struct {
+	goType string
+	dbType func(size int) string
+}
+
+// fieldTypes 支持的字段类型
+var fieldTypes = map[string]fieldType{
+	"string":  {goType: "string", dbType: func(size int) string { return fmt.Sprintf("varchar(%d)", size) }},
+	"text":    {goType: "string", dbType: func(int) string { return "text" }},
+	"int":     {goType: "int", dbType: func(int) string { return "" }},
+	"int64":   {goType: "int64", dbType: func(int) string { return "" }},
+	"uint":    {goType: "uint", dbType: func(int) string { return "" }},
+	"float64": {goType: "float64", dbType: func(int) string { return "" }},
+	"bool":    {goType: "bool", dbType: func(int) string { return "" }},
+	"time":    {goType: "time.Time", dbType: func(int) string { return "" }},
+}
+
+var identPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// reservedFields BaseModel 已包含的字段
+var reservedFields = map[string]bool{"id": true, "created_at": true, "updated_at": true, "deleted_at": true}
+
+// LoadSpec 从YAML文件读取模块定义
+func LoadSpec(path string) (*ModuleSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取模块定义失败: %w", err)
+	}
+
+	var spec ModuleSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("解析模块定义失败: %w", err)
+	}
+	return &spec, nil
+}
+
+// ParseField 解析命令行字段定义，格式: 名称:类型[:选项,选项...]
+// 选项: required、unique、index、filter、hidden、size=N、comment=说明
+// 示例: order_no:string:required,unique,size=64,filter,comment=订单号
+func ParseField(s string) (FieldSpec, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 {
+		return FieldSpec{}, fmt.Errorf("字段定义格式应为 名称:类型[:选项]: %q", s)
+	}
+
+	field := FieldSpec{Name: parts[0], Type: parts[1]}
+	if len(parts) == 3 {
+		for _, opt := range strings.Split(parts[2], ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch key {
+			case "":
+			case "required":
+				field.Required = true
+			case "unique":
+				field.Unique = true
+			case "index":
+				field.Index = true
+			case "filter":
+				field.Filter = true
+			case "hidden":
+				field.Hidden = true
+			case "size":
+				size, err := strconv.Atoi(value)
+				if err != nil {
+					return FieldSpec{}, fmt.Errorf("字段 %s 的 size 必须是整数: %q", field.Name, value)
+				}
+				field.Size = size
+			case "comment":
+				field.Comment = value
+			default:
+				return FieldSpec{}, fmt.Errorf("字段 %s 的选项 %q 不支持", field.Name, key)
+			}
+		}
+	}
+	return field, nil
+}
+
+// Validate 校验模块定义并填充默认值
+func (s *ModuleSpec) Validate() error {
+	if !identPattern.MatchString(s.Name) {
+		return fmt.Errorf("模块名 %q 不合法，只能包含字母、数字和下划线且以字母开头", s.Name)
+	}
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("模块 %s 至少需要一个字段", s.Name)
+	}
+
+	snake := toSnake(s.Name)
+	if s.Comment == "" {
+		s.Comment = toPascal(snake)
+	}
+	if s.Table == "" {
+		s.Table = pluralize(snake)
+	}
+	if s.Route == "" {
+		s.Route = "/" + strings.ReplaceAll(pluralize(snake), "_", "-")
+	}
+	if !strings.HasPrefix(s.Route, "/") {
+		s.Route = "/" + s.Route
+	}
+
+	seen := make(map[string]bool, len(s.Fields))
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if !identPattern.MatchString(f.Name) {
+			return fmt.Errorf("字段名 %q 不合法", f.Name)
+		}
+		f.Name = toSnake(f.Name)
+		if reservedFields[f.Name] {
+			return fmt.Errorf("字段 %s 已由 BaseModel 提供", f.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("字段 %s 重复", f.Name)
+		}
+		seen[f.Name] = true
+
+		if _, ok := fieldTypes[f.Type]; !ok {
+			return fmt.Errorf("字段 %s 的类型 %q 不支持，可选: string, text, int, int64, uint, float64, bool, time", f.Name, f.Type)
+		}
+		if f.Type == "string" && f.Size <= 0 {
+			f.Size = defaultStringSize
+		}
+		if f.Filter && (f.Type == "text" || f.Type == "time") {
+			return fmt.Errorf("字段 %s 的类型 %s 不支持作为查询条件", f.Name, f.Type)
+		}
+		if f.Comment == "" {
+			f.Comment = toPascal(f.Name)
+		}
+	}
+	return nil
+}
+
+// toSnake 转换为snake_case，已是snake_case的名称保持不变
+func toSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && name[i-1] != '_' {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toPascal 将snake_case转换为PascalCase，常见缩写全大写
+func toPascal(snake string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(snake, "_") {
+		if part == "" {
+			continue
+		}
+		if initialisms[part] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// toCamel 将snake_case转换为camelCase
+func toCamel(snake string) string {
+	pascal := toPascal(snake)
+	for i, r := range pascal {
+		if r < 'A' || r > 'Z' {
+			if i > 1 {
+				// 以缩写开头时保留最后一个大写字母，如 URLPath -> urlPath
+				return strings.ToLower(pascal[:i-1]) + pascal[i-1:]
+			}
+			return strings.ToLower(pascal[:i]) + pascal[i:]
+		}
+	}
+	return strings.ToLower(pascal)
+}
+
+// pluralize 英文复数形式，只处理常见规则
+func pluralize(snake string) string {
+	switch {
+	case strings.HasSuffix(snake, "y") && len(snake) > 1 && !strings.ContainsRune("aeiou", rune(snake[len(snake)-2])):
+		return snake[:len(snake)-1] + "ies"
+	case strings.HasSuffix(snake, "s"), strings.HasSuffix(snake, "x"),
+		strings.HasSuffix(snake, "ch"), strings.HasSuffix(snake, "sh"):
+		return snake + "es"
+	default:
+		return snake + "s"
+	}
+}
+
+// initialisms 生成Go标识符时全大写的缩写
+var initialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "ip": true, "api": true, "http": true,
+	"json": true, "sql": true, "uuid": true, "sku": true,
+}
@@ -0,0 +1,50 @@
+// Package handler HTTP处理层
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/model"
+	"ruleback/internal/service"
+	"ruleback/pkg/errors"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/response"
+)
+
+// AuditLogHandler 变更历史HTTP处理器
+type AuditLogHandler struct {
+	service *service.AuditLogService
+}
+
+// NewAuditLogHandler 创建AuditLogHandler实例（用于Wire依赖注入）
+func NewAuditLogHandler(svc *service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{service: svc}
+}
+
+// History 获取指定记录的变更历史
+func (h *AuditLogHandler) History(c *gin.Context) {
+	var query model.AuditLogQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.Warn("获取变更历史参数错误", logger.Err(err))
+		response.Fail(c, errors.CodeInvalidParams, "参数错误: "+err.Error())
+		return
+	}
+
+	logs, total, err := h.service.History(c.Request.Context(), &query)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, logs, total, query.Page, query.PageSize)
+}
+
+// handleError 统一处理错误响应
+func (h *AuditLogHandler) handleError(c *gin.Context, err error) {
+	appErr := errors.GetAppError(err)
+	if appErr != nil {
+		response.Fail(c, appErr.Code, appErr.Message)
+		return
+	}
+	logger.Error("处理请求时发生未知错误", logger.Err(err))
+	response.Fail(c, errors.CodeInternalError, "服务器内部错误")
+}
@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"ruleback/internal/config"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/reqctx"
+)
+
+// apacheTimeFormat Apache访问日志的时间格式
+const apacheTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLogPolicy 由 config.AccessLogConfig 编译得到的访问日志策略
+type accessLogPolicy struct {
+	source   *config.Config
+	format   string
+	excluded map[string]bool
+	prefixes []string
+	sampling map[string]float64
+}
+
+// accessEntry 一次请求的访问日志数据
+type accessEntry struct {
+	start     time.Time
+	latency   time.Duration
+	method    string
+	path      string
+	proto     string
+	route     string
+	clientIP  string
+	userAgent string
+	referer   string
+	status    int
+	bytesIn   int64
+	bytesOut  int
+	userID    uint
+	hasUser   bool
+}
+
+// Logger 访问日志中间件，配置来自 log.access，配置热更新后自动生效
+// 按 exclude_paths 跳过路径，按 sampling 对成功请求采样；structured 格式按状态码选择级别（5xx为Error，4xx为Warn）
+func Logger() gin.HandlerFunc {
+	var current atomic.Pointer[accessLogPolicy]
+
+	return func(c *gin.Context) {
+		policy := current.Load()
+		if cfg := config.Get(); policy == nil || policy.source != cfg {
+			policy = newAccessLogPolicy(cfg)
+			current.Store(policy)
+		}
+
+		path := c.Request.URL.Path
+		if policy.isExcluded(path) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		entry := accessEntry{
+			start:     start,
+			latency:   time.Since(start),
+			method:    c.Request.Method,
+			path:      path,
+			proto:     c.Request.Proto,
+			route:     c.FullPath(),
+			clientIP:  c.ClientIP(),
+			userAgent: c.Request.UserAgent(),
+			referer:   c.Request.Referer(),
+			status:    c.Writer.Status(),
+			bytesIn:   c.Request.ContentLength,
+			bytesOut:  c.Writer.Size(),
+		}
+		if c.Request.URL.RawQuery != "" {
+			entry.path += "?" + c.Request.URL.RawQuery
+		}
+		if entry.bytesIn < 0 {
+			entry.bytesIn = 0
+		}
+		if entry.bytesOut < 0 {
+			entry.bytesOut = 0
+		}
+		entry.userID, entry.hasUser = reqctx.GetUserID(c.Request.Context())
+
+		if !policy.sampled(entry.route, entry.status) {
+			return
+		}
+
+		switch policy.format {
+		case "combined", "common":
+			fmt.Fprintln(logger.Output(), entry.apacheLine(policy.format == "combined"))
+		default:
+			entry.log(c)
+		}
+	}
+}
+
+// newAccessLogPolicy 编译访问日志配置，cfg 为nil时记录所有请求
+func newAccessLogPolicy(cfg *config.Config) *accessLogPolicy {
+	p := &accessLogPolicy{
+		source:   cfg,
+		format:   "structured",
+		excluded: make(map[string]bool),
+		sampling: make(map[string]float64),
+	}
+	if cfg == nil {
+		return p
+	}
+
+	access := cfg.Log.Access
+	p.format = access.Format
+	for _, path := range access.ExcludePaths {
+		if prefix, ok := strings.CutSuffix(path, "*"); ok {
+			p.prefixes = append(p.prefixes, prefix)
+		} else {
+			p.excluded[path] = true
+		}
+	}
+	for _, s := range access.Sampling {
+		p.sampling[s.Route] = s.Rate
+	}
+	return p
+}
+
+// isExcluded 路径是否不记录访问日志
+func (p *accessLogPolicy) isExcluded(path string) bool {
+	if p.excluded[path] {
+		return true
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sampled 是否记录本次请求，4xx/5xx请求始终记录
+func (p *accessLogPolicy) sampled(route string, status int) bool {
+	rate, ok := p.sampling[route]
+	if !ok || status >= http.StatusBadRequest {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+// log 输出结构化访问日志，级别由状态码决定
+func (e *accessEntry) log(c *gin.Context) {
+	fields := []zap.Field{
+		logger.String("method", e.method),
+		logger.String("path", e.path),
+		logger.String("route", e.route),
+		logger.String("ip", e.clientIP),
+		logger.Int("status", e.status),
+		logger.Float64("latency_ms", float64(e.latency.Milliseconds())),
+		logger.Int64("bytes_in", e.bytesIn),
+		logger.Int("bytes_out", e.bytesOut),
+		logger.String("user_agent", e.userAgent),
+		logger.String("referer", e.referer),
+	}
+	if e.hasUser {
+		fields = append(fields, logger.Uint("user_id", e.userID))
+	}
+
+	ctx := c.Request.Context()
+	switch {
+	case e.status >= http.StatusInternalServerError:
+		logger.ErrorContext(ctx, "HTTP请求", fields...)
+	case e.status >= http.StatusBadRequest:
+		logger.WarnContext(ctx, "HTTP请求", fields...)
+	default:
+		logger.InfoContext(ctx, "HTTP请求", fields...)
+	}
+}
+
+// apacheLine 生成Apache common/combined格式的访问日志行
+//
+//	common:   %h %l %u %t "%r" %>s %b
+//	combined: common + "%{Referer}i" "%{User-Agent}i"
+func (e *accessEntry) apacheLine(combined bool) string {
+	user := "-"
+	if e.hasUser {
+		user = strconv.FormatUint(uint64(e.userID), 10)
+	}
+	size := "-"
+	if e.bytesOut > 0 {
+		size = strconv.Itoa(e.bytesOut)
+	}
+
+	line := fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`,
+		e.clientIP, user, e.start.Format(apacheTimeFormat),
+		e.method, apacheEscape(e.path), e.proto, e.status, size)
+	if combined {
+		line += fmt.Sprintf(` "%s" "%s"`, apacheEscape(orDash(e.referer)), apacheEscape(orDash(e.userAgent)))
+	}
+	return line
+}
+
+// apacheEscape 转义引号、反斜杠和控制字符，避免客户端伪造日志行
+func apacheEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, "\\x%02x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// orDash 空值显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,314 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"ruleback/internal/config"
+	"ruleback/pkg/logger"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "******"
+
+var (
+	// defaultRedactFields 始终脱敏的字段名，在任意层级匹配，不区分大小写
+	defaultRedactFields = []string{"password", "old_password", "new_password", "token", "access_token", "refresh_token", "secret", "api_key"}
+	// defaultRedactHeaders 始终脱敏的请求头和响应头
+	defaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+)
+
+// bodyLogPolicy 由 config.BodyLogConfig 编译得到的请求体日志策略
+type bodyLogPolicy struct {
+	source     *config.Config
+	enabled    bool
+	routes     map[string]bool
+	errorsOnly bool
+	maxBytes   int
+	names      map[string]bool // 任意层级匹配的字段名（小写）
+	paths      [][]string      // 从根开始匹配的JSON路径，* 匹配任意键
+	headers    map[string]bool // 规范化后的请求头名
+}
+
+// bodyLogWriter 在写出响应的同时保留前 limit 字节
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	buf   bytes.Buffer
+	limit int
+	total int
+}
+
+// Write 写出响应并记录
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString 写出响应并记录
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 记录不超过 limit 的部分，多保留1字节用于判断是否截断
+func (w *bodyLogWriter) capture(b []byte) {
+	w.total += len(b)
+	if room := w.limit + 1 - w.buf.Len(); room > 0 {
+		if len(b) > room {
+			b = b[:room]
+		}
+		w.buf.Write(b)
+	}
+}
+
+// BodyLogger 请求/响应体日志中间件，配置来自 log.body，默认关闭，配置热更新后自动生效
+// 记录脱敏后的请求头、JSON和表单请求体、响应体；JSON超过 max_bytes 时无法完整解析和脱敏、其他类型无法按字段脱敏，只记录大小
+func BodyLogger() gin.HandlerFunc {
+	var current atomic.Pointer[bodyLogPolicy]
+
+	return func(c *gin.Context) {
+		policy := current.Load()
+		if cfg := config.Get(); policy == nil || policy.source != cfg {
+			policy = newBodyLogPolicy(cfg)
+			current.Store(policy)
+		}
+		if !policy.enabled || (len(policy.routes) > 0 && !policy.routes[c.FullPath()]) {
+			c.Next()
+			return
+		}
+
+		var reqBody []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			// 读取前 max_bytes+1 字节后放回，Handler 仍能读到完整请求体
+			reqBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(policy.maxBytes)+1))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(reqBody), c.Request.Body), c.Request.Body}
+		}
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, limit: policy.maxBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if policy.errorsOnly && status < http.StatusBadRequest {
+			return
+		}
+
+		fields := []zap.Field{
+			logger.String("method", c.Request.Method),
+			logger.String("route", c.FullPath()),
+			logger.Int("status", status),
+			logger.Field("request_headers", policy.redactHeaders(c.Request.Header)),
+			logger.Field("response_headers", policy.redactHeaders(writer.Header())),
+		}
+		fields = append(fields, policy.bodyFields("request_body", c.ContentType(), reqBody, int(c.Request.ContentLength))...)
+		fields = append(fields, policy.bodyFields("response_body", writer.Header().Get("Content-Type"), writer.buf.Bytes(), writer.total)...)
+		logger.InfoContext(c.Request.Context(), "HTTP请求体", fields...)
+	}
+}
+
+// readCloser 组合读取和关闭，放回请求体时保留原始Body的Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// newBodyLogPolicy 编译请求体日志配置，cfg 为nil或未开启时不记录
+func newBodyLogPolicy(cfg *config.Config) *bodyLogPolicy {
+	p := &bodyLogPolicy{
+		source:  cfg,
+		routes:  make(map[string]bool),
+		names:   make(map[string]bool),
+		headers: make(map[string]bool),
+	}
+	if cfg == nil || !cfg.Log.Body.Enabled {
+		return p
+	}
+
+	body := cfg.Log.Body
+	p.enabled = true
+	p.errorsOnly = body.ErrorsOnly
+	p.maxBytes = body.MaxBytes
+	for _, route := range body.Routes {
+		p.routes[route] = true
+	}
+	for _, field := range append(append([]string{}, defaultRedactFields...), body.RedactFields...) {
+		if segments := strings.Split(strings.ToLower(field), "."); len(segments) > 1 {
+			p.paths = append(p.paths, segments)
+		} else {
+			p.names[segments[0]] = true
+		}
+	}
+	for _, h := range append(append([]string{}, defaultRedactHeaders...), body.RedactHeaders...) {
+		p.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	return p
+}
+
+// redactHeaders 返回脱敏后的头，多个值用逗号连接
+func (p *bodyLogPolicy) redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if p.headers[http.CanonicalHeaderKey(name)] {
+			out[name] = redactedValue
+		} else {
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+// bodyFields 生成请求体或响应体的日志字段，captured 最多比 max_bytes 多1字节
+func (p *bodyLogPolicy) bodyFields(key, contentType string, captured []byte, size int) []zap.Field {
+	if size < len(captured) {
+		size = len(captured)
+	}
+	if len(captured) == 0 {
+		return nil
+	}
+
+	truncated := len(captured) > p.maxBytes
+	if truncated {
+		captured = captured[:p.maxBytes]
+	}
+	fields := []zap.Field{logger.Int(key+"_size", size)}
+	if truncated {
+		fields = append(fields, logger.Bool(key+"_truncated", true))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if truncated {
+			// 不完整的JSON无法脱敏，不输出内容
+			return fields
+		}
+		if redacted, ok := p.redactJSON(captured); ok {
+			return append(fields, logger.Field(key, redacted))
+		}
+		return fields
+	case mediaType == "application/x-www-form-urlencoded":
+		if truncated {
+			return fields
+		}
+		if redacted, ok := p.redactForm(captured); ok {
+			return append(fields, logger.String(key, redacted))
+		}
+		return fields
+	default:
+		// 文本、XML无法按字段脱敏，二进制和 multipart 无法阅读，只记录大小
+		return fields
+	}
+}
+
+// redactJSON 解析JSON并替换需要脱敏的字段，解析失败时返回false
+func (p *bodyLogPolicy) redactJSON(body []byte) (interface{}, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	p.redactNames(value)
+	for _, path := range p.paths {
+		redactPath(value, path)
+	}
+	return value, true
+}
+
+// redactNames 在任意层级替换与字段名匹配的值
+func (p *bodyLogPolicy) redactNames(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if p.names[strings.ToLower(k)] {
+				v[k] = redactedValue
+				continue
+			}
+			p.redactNames(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			p.redactNames(child)
+		}
+	}
+}
+
+// redactPath 从根开始按路径替换，* 匹配任意键或数组元素，数组未写 * 时也会展开到每个元素
+func redactPath(value interface{}, path []string) {
+	switch v := value.(type) {
+	case []interface{}:
+		rest := path
+		if path[0] == "*" {
+			rest = path[1:]
+		}
+		for i, child := range v {
+			if len(rest) == 0 {
+				v[i] = redactedValue
+			} else {
+				redactPath(child, rest)
+			}
+		}
+	case map[string]interface{}:
+		for k, child := range v {
+			if path[0] != "*" && strings.ToLower(k) != path[0] {
+				continue
+			}
+			if len(path) == 1 {
+				v[k] = redactedValue
+			} else {
+				redactPath(child, path[1:])
+			}
+		}
+	}
+}
+
+// redactForm 替换表单中与字段名或路径末段匹配的值，解析失败时返回false
+func (p *bodyLogPolicy) redactForm(body []byte) (string, bool) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", false
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		redact := p.names[strings.ToLower(k)] || p.matchesPathTail(k)
+		for _, v := range values[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k) + "=")
+			if redact {
+				b.WriteString(redactedValue)
+			} else {
+				b.WriteString(url.QueryEscape(v))
+			}
+		}
+	}
+	return b.String(), true
+}
+
+// matchesPathTail 表单没有嵌套结构，按JSON路径的最后一段匹配
+func (p *bodyLogPolicy) matchesPathTail(name string) bool {
+	name = strings.ToLower(name)
+	for _, path := range p.paths {
+		if path[len(path)-1] == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
+	"ruleback/pkg/httpcache"
+	"ruleback/pkg/logger"
+)
+
+const (
+	// CacheStatusHeader 标记响应是否来自服务端缓存，值为 HIT 或 MISS
+	CacheStatusHeader = "X-Cache"
+	// maxCachedResponseBytes 可缓存的响应体上限
+	maxCachedResponseBytes = 1 << 20
+)
+
+// cachePolicy 由 config.HTTPCacheConfig 编译得到的缓存策略
+type cachePolicy struct {
+	source  *config.Config
+	enabled bool
+	ttls    map[string]time.Duration
+}
+
+// Cache 服务端响应缓存中间件，注册在GET路由上，需要认证的路由必须放在认证中间件之后
+// 缓存键包含调用方身份、路径和查询参数，只缓存200、响应码为0且不带 Set-Cookie 的响应；http_cache.enabled 为false时直接放行
+// ttl 可被 http_cache.routes 按路由模板覆盖；tags 用于 httpcache.Invalidate，{name} 替换为路由参数，如 "product:{id}"
+//
+//	products.GET("/:id", middleware.Cache(5*time.Minute, "products", "product:{id}"), h.GetByID)
+func Cache(ttl time.Duration, tags ...string) gin.HandlerFunc {
+	var current atomic.Pointer[cachePolicy]
+
+	return func(c *gin.Context) {
+		policy := current.Load()
+		if cfg := config.Get(); policy == nil || policy.source != cfg {
+			policy = newCachePolicy(cfg)
+			current.Store(policy)
+		}
+		if !policy.enabled || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		routeTTL := ttl
+		if override, ok := policy.ttls[c.FullPath()]; ok {
+			routeTTL = override
+		}
+		if routeTTL <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		store := httpcache.GetStore()
+		key := cacheKey(c)
+		entry, err := store.Get(ctx, key)
+		if err != nil {
+			logger.WarnContext(ctx, "读取响应缓存失败", logger.Err(err))
+		}
+		if entry != nil {
+			writeCachedResponse(c, entry)
+			c.Abort()
+			return
+		}
+
+		now := time.Now()
+		c.Header(CacheStatusHeader, "MISS")
+		// Handler 可用 response.SetLastModified 覆盖；缓存命中时返回相同的时间，客户端可用 If-Modified-Since 验证
+		c.Header("Last-Modified", now.UTC().Format(http.TimeFormat))
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: maxCachedResponseBytes}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK || !isSuccessResponse(c) || writer.overflow || writer.Header().Get("Set-Cookie") != "" {
+			return
+		}
+		entry = &httpcache.Entry{
+			Status:    http.StatusOK,
+			Header:    replayableHeader(writer.Header()),
+			Body:      writer.buf.Bytes(),
+			Tags:      expandCacheTags(c, tags),
+			StoredAt:  now,
+			ExpiresAt: now.Add(routeTTL),
+		}
+		if err := store.Set(context.WithoutCancel(ctx), key, entry); err != nil {
+			logger.WarnContext(ctx, "保存响应缓存失败", logger.Err(err))
+		}
+	}
+}
+
+// newCachePolicy 编译缓存配置，cfg 为nil时不缓存
+func newCachePolicy(cfg *config.Config) *cachePolicy {
+	p := &cachePolicy{source: cfg, ttls: make(map[string]time.Duration)}
+	if cfg == nil || !cfg.HTTPCache.Enabled {
+		return p
+	}
+	p.enabled = true
+	for _, r := range cfg.HTTPCache.Routes {
+		p.ttls[r.Route] = time.Duration(r.TTL) * time.Second
+	}
+	return p
+}
+
+// cacheKey 缓存键：调用方身份 + 路径 + 排序后的查询参数
+// 未设置用户ID和调用方身份时使用 Authorization 的摘要，不同凭证的响应互不共享
+func cacheKey(c *gin.Context) string {
+	identity, ok := callerIdentity(c.Request.Context())
+	if !ok {
+		identity = "anonymous"
+		if auth := c.GetHeader("Authorization"); auth != "" {
+			identity = "authorization:" + hashParts(auth)
+		}
+	}
+	return hashParts(identity, c.Request.URL.Path, c.Request.URL.Query().Encode())
+}
+
+// expandCacheTags 将标签中的 {name} 替换为路由参数
+func expandCacheTags(c *gin.Context, tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	expanded := make([]string, len(tags))
+	for i, tag := range tags {
+		for _, p := range c.Params {
+			tag = strings.ReplaceAll(tag, "{"+p.Key+"}", p.Value)
+		}
+		expanded[i] = tag
+	}
+	return expanded
+}
+
+// writeCachedResponse 写出缓存的响应，保留本次请求的 X-Request-ID 和跨域头
+func writeCachedResponse(c *gin.Context, entry *httpcache.Entry) {
+	header := c.Writer.Header()
+	for name, values := range entry.Header {
+		if skipReplayHeader(name) {
+			continue
+		}
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set(CacheStatusHeader, "HIT")
+	c.Writer.WriteHeader(entry.Status)
+	_, _ = c.Writer.Write(entry.Body)
+}
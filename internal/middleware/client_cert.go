@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"crypto/x509"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/reqctx"
+	"ruleback/pkg/response"
+)
+
+// clientIdentityKey 客户端证书身份在gin上下文中的键名
+const clientIdentityKey = "client_identity"
+
+// ClientIdentity 已校验的客户端证书身份
+type ClientIdentity struct {
+	Principal string // 按 identity_by 从证书中取得的身份
+	Subject   string // 证书主题，如 CN=billing-service,O=Example
+	Serial    string // 证书序列号
+	UserID    uint   // principals 中映射的用户ID，未映射时为0
+}
+
+// ClientCert 客户端证书认证中间件，可替代 Auth 用于 RegisterAuthenticatedRoutes
+// 证书由TLS握手按 server.tls.client_auth 校验，本中间件只读取已校验的证书并映射身份
+func ClientCert() gin.HandlerFunc {
+	var cfg config.ClientAuthConfig
+	if c := config.Get(); c != nil {
+		cfg = c.Server.TLS.ClientAuth
+	}
+	if !cfg.IsEnabled() {
+		logger.Warn("未开启 server.tls.client_auth，使用客户端证书认证的路由将拒绝所有请求")
+	}
+
+	principals := make(map[string]uint, len(cfg.Principals))
+	for _, p := range cfg.Principals {
+		principals[p.Name] = p.UserID
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.TLS == nil || len(c.Request.TLS.VerifiedChains) == 0 {
+			response.Unauthorized(c, "未提供有效的客户端证书")
+			c.Abort()
+			return
+		}
+
+		cert := c.Request.TLS.VerifiedChains[0][0]
+		principal, userID, ok := matchPrincipal(certIdentities(cert, cfg.IdentityBy), principals)
+		if !ok {
+			logger.Warn("客户端证书身份未授权",
+				logger.String("subject", cert.Subject.String()),
+				logger.String("identity_by", cfg.IdentityBy),
+			)
+			response.Forbidden(c, "客户端证书未授权")
+			c.Abort()
+			return
+		}
+
+		c.Set(clientIdentityKey, &ClientIdentity{
+			Principal: principal,
+			Subject:   cert.Subject.String(),
+			Serial:    cert.SerialNumber.String(),
+			UserID:    userID,
+		})
+		c.Request = c.Request.WithContext(reqctx.WithPrincipal(c.Request.Context(), principal))
+		if userID != 0 {
+			SetUserID(c, userID)
+		}
+
+		c.Next()
+	}
+}
+
+// GetClientIdentity 获取 ClientCert 中间件写入的客户端身份
+func GetClientIdentity(c *gin.Context) (*ClientIdentity, bool) {
+	identity, ok := c.Get(clientIdentityKey)
+	if !ok {
+		return nil, false
+	}
+	id, ok := identity.(*ClientIdentity)
+	return id, ok
+}
+
+// certIdentities 按配置从证书中取出候选身份
+func certIdentities(cert *x509.Certificate, identityBy string) []string {
+	switch identityBy {
+	case "dns_san":
+		return cert.DNSNames
+	case "uri_san":
+		uris := make([]string, 0, len(cert.URIs))
+		for _, u := range cert.URIs {
+			uris = append(uris, u.String())
+		}
+		return uris
+	case "email_san":
+		return cert.EmailAddresses
+	default:
+		if cert.Subject.CommonName == "" {
+			return nil
+		}
+		return []string{cert.Subject.CommonName}
+	}
+}
+
+// matchPrincipal 选出允许访问的身份
+// 未配置 principals 时接受第一个候选身份；证书包含多个SAN时取第一个在 principals 中的
+func matchPrincipal(candidates []string, principals map[string]uint) (string, uint, bool) {
+	if len(principals) == 0 {
+		if len(candidates) == 0 {
+			return "", 0, false
+		}
+		return candidates[0], 0, true
+	}
+
+	for _, name := range candidates {
+		if userID, ok := principals[name]; ok {
+			return name, userID, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
+)
+
+// corsPolicy 由 config.CORSConfig 编译得到的跨域策略
+type corsPolicy struct {
+	source           *config.Config
+	allowAll         bool
+	origins          map[string]bool
+	wildcards        []originPattern
+	methods          map[string]bool
+	allowAllHeaders  bool
+	headers          map[string]bool
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// originPattern 子域名通配来源，如 https://*.example.com 拆分为 https:// 和 .example.com
+type originPattern struct {
+	prefix string
+	suffix string
+}
+
+// CORS 跨域处理中间件，策略来自 cors 配置，配置热更新后自动生效
+// 只有带 Origin 和 Access-Control-Request-Method 的 OPTIONS 请求按预检处理，其他 OPTIONS 请求交给路由
+func CORS() gin.HandlerFunc {
+	var current atomic.Pointer[corsPolicy]
+
+	return func(c *gin.Context) {
+		// 响应内容随 Origin 变化，无 Origin 的请求也需要声明，避免缓存把无跨域头的响应返回给跨域请求
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Writer.Header().Add("Vary", "Origin")
+			c.Next()
+			return
+		}
+
+		policy := current.Load()
+		if cfg := config.Get(); policy == nil || policy.source != cfg {
+			policy = newCORSPolicy(cfg)
+			current.Store(policy)
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			policy.preflight(c, origin)
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if policy.allowOrigin(origin) {
+			policy.setOriginHeaders(c, origin)
+			if policy.exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", policy.exposeHeaders)
+			}
+		}
+		c.Next()
+	}
+}
+
+// preflight 处理预检请求，来源、方法或请求头不允许时返回403且不带跨域响应头
+func (p *corsPolicy) preflight(c *gin.Context, origin string) {
+	h := c.Writer.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+	requested := parseHeaderList(c.GetHeader("Access-Control-Request-Headers"))
+	if !p.allowOrigin(origin) || !p.methods[method] || !p.allowRequestHeaders(requested) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	p.setOriginHeaders(c, origin)
+	c.Header("Access-Control-Allow-Methods", p.allowMethods)
+	if len(requested) > 0 {
+		// 允许任意请求头时回显请求的头，"*" 在携带凭证时不生效
+		allowHeaders := p.allowHeaders
+		if p.allowAllHeaders {
+			allowHeaders = strings.Join(requested, ", ")
+		}
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+	}
+	c.Header("Access-Control-Max-Age", p.maxAge)
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// setOriginHeaders 写入允许的来源和凭证响应头
+func (p *corsPolicy) setOriginHeaders(c *gin.Context, origin string) {
+	if p.allowAll && !p.allowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.allowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowOrigin 判断来源是否允许
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		if len(origin) > len(w.prefix)+len(w.suffix) && strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix) {
+			// 通配部分必须是完整的子域名标签，不能包含端口或路径
+			label := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+			if !strings.ContainsAny(label, ":/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowRequestHeaders 判断预检请求声明的请求头是否全部允许
+func (p *corsPolicy) allowRequestHeaders(requested []string) bool {
+	if p.allowAllHeaders {
+		return true
+	}
+	for _, h := range requested {
+		if !p.headers[strings.ToLower(h)] {
+			return false
+		}
+	}
+	return true
+}
+
+// newCORSPolicy 编译跨域配置，配置未加载时不允许任何跨域请求
+func newCORSPolicy(cfg *config.Config) *corsPolicy {
+	p := &corsPolicy{
+		source:  cfg,
+		origins: map[string]bool{},
+		methods: map[string]bool{},
+		headers: map[string]bool{},
+	}
+	if cfg == nil {
+		return p
+	}
+	cors := cfg.CORS
+
+	for _, origin := range cors.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			p.allowAll = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://*.")
+			p.wildcards = append(p.wildcards, originPattern{prefix: scheme + "://", suffix: "." + host})
+		default:
+			p.origins[origin] = true
+		}
+	}
+
+	methods := make([]string, 0, len(cors.AllowedMethods))
+	for _, m := range cors.AllowedMethods {
+		m = strings.ToUpper(strings.TrimSpace(m))
+		p.methods[m] = true
+		methods = append(methods, m)
+	}
+
+	for _, h := range cors.AllowedHeaders {
+		h = strings.TrimSpace(h)
+		if h == "*" {
+			p.allowAllHeaders = true
+			continue
+		}
+		p.headers[strings.ToLower(h)] = true
+	}
+
+	exposed := cors.ExposedHeaders
+	if !containsFold(exposed, "X-Request-ID") {
+		exposed = append(append([]string{}, exposed...), "X-Request-ID")
+	}
+
+	p.allowMethods = strings.Join(methods, ", ")
+	p.allowHeaders = strings.Join(cors.AllowedHeaders, ", ")
+	p.exposeHeaders = strings.Join(exposed, ", ")
+	p.allowCredentials = cors.AllowCredentials
+	p.maxAge = strconv.Itoa(cors.MaxAge)
+	return p
+}
+
+// parseHeaderList 解析逗号分隔的请求头列表
+func parseHeaderList(value string) []string {
+	var headers []string
+	for _, h := range strings.Split(value, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
+// containsFold 判断列表中是否包含指定值（忽略大小写）
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/pkg/response"
+)
+
+// DiagnosticsTokenHeader 访问诊断端点的令牌请求头，值为 diagnostics.token
+const DiagnosticsTokenHeader = "X-Diagnostics-Token"
+
+// DiagnosticsToken 诊断端点令牌校验中间件，请求头 X-Diagnostics-Token 与 token 相同时放行，否则返回401
+// 与业务认证相互独立，不依赖 Auth 的实现；token 为空时拒绝所有请求
+func DiagnosticsToken(token string) gin.HandlerFunc {
+	want := sha256.Sum256([]byte(token))
+	return func(c *gin.Context) {
+		got := c.GetHeader(DiagnosticsTokenHeader)
+		// 比较摘要使耗时与令牌内容和长度无关
+		sum := sha256.Sum256([]byte(got))
+		if token == "" || got == "" || subtle.ConstantTimeCompare(sum[:], want[:]) != 1 {
+			response.Unauthorized(c, "诊断令牌无效")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
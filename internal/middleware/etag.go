@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/pkg/response"
+)
+
+// etagWriter 缓存整个响应，Handler 结束后计算ETag再决定写出完整响应还是304
+// Handler 调用 Flush 时切换为直接写出，不再计算ETag
+type etagWriter struct {
+	gin.ResponseWriter
+	status    int
+	buf       bytes.Buffer
+	written   bool
+	streaming bool
+}
+
+// WriteHeader 记录状态码
+func (w *etagWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 标记响应已写出
+func (w *etagWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.written = true
+}
+
+// Write 缓存响应体
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	w.written = true
+	return w.buf.Write(b)
+}
+
+// WriteString 缓存响应体
+func (w *etagWriter) WriteString(s string) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.WriteString(s)
+	}
+	w.written = true
+	return w.buf.WriteString(s)
+}
+
+// Status 返回记录的状态码
+func (w *etagWriter) Status() int {
+	if w.streaming || w.status == 0 {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Size 返回已缓存的响应体大小，未写出时为-1
+func (w *etagWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+// Written 响应是否已写出
+func (w *etagWriter) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.written
+}
+
+// Flush 写出已缓存的内容并切换为直接写出
+func (w *etagWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.writeBuffered()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// writeBuffered 写出记录的状态码和缓存的响应体
+func (w *etagWriter) writeBuffered() {
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	} else if w.written {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+	w.buf.Reset()
+}
+
+// ETag 条件请求中间件，注册在API路由分组上
+// 为 GET/HEAD 的200成功响应按响应体计算ETag（Handler 已设置 ETag 时沿用），响应码非0的业务错误不计算，
+// If-None-Match 匹配或未携带 If-None-Match 且 Last-Modified 不晚于 If-Modified-Since 时返回304
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &etagWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		finished := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			if finished {
+				writer.finish(c.Request, isSuccessResponse(c))
+				return
+			}
+			// panic时丢弃已缓存的内容，由 Recovery 直接写出错误响应
+			if writer.written && !writer.streaming {
+				writer.Header().Del("Content-Type")
+				writer.Header().Del("Content-Length")
+			}
+		}()
+
+		c.Next()
+		finished = true
+	}
+}
+
+// finish 计算ETag并写出响应或304，success 为false时直接写出
+func (w *etagWriter) finish(r *http.Request, success bool) {
+	if w.streaming {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	if success && w.Status() == http.StatusOK && w.buf.Len() > 0 {
+		etag := header.Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(w.buf.Bytes())
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+		if isNotModified(r, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			w.ResponseWriter.WriteHeaderNow()
+			return
+		}
+	}
+	w.writeBuffered()
+}
+
+// isSuccessResponse 响应是否成功：response 包写出的响应码必须为0，其他方式写出的响应（如文件）按HTTP状态码判断
+// response.Fail 的HTTP状态码为200，不能只看状态码
+func isSuccessResponse(c *gin.Context) bool {
+	code, ok := response.GetCode(c)
+	return !ok || code == 0
+}
+
+// isNotModified 按 RFC 9110 判断条件请求，If-None-Match 优先于 If-Modified-Since
+func isNotModified(r *http.Request, etag, lastModified string) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatches If-None-Match 中是否有与 etag 弱比较相等的值
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
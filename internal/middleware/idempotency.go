@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
+	apperrors "ruleback/pkg/errors"
+	"ruleback/pkg/idempotency"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/reqctx"
+	"ruleback/pkg/response"
+)
+
+const (
+	// IdempotencyKeyHeader 客户端生成的幂等键请求头，同一个操作的重试使用相同的值
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 响应为重放首次响应时设置为 true
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength 幂等键最大长度
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentResponseBytes 保存的响应体上限，超过时不保存，重试会再次执行
+	maxIdempotentResponseBytes = 1 << 20
+)
+
+// replaySkipHeaders 保存和重放响应或缓存时跳过的响应头，这些头属于本次请求，由本次请求的中间件设置
+var replaySkipHeaders = map[string]bool{
+	"X-Request-Id":   true,
+	"Content-Length": true,
+	"Date":           true,
+	"Vary":           true,
+}
+
+// skipReplayHeader 是否跳过响应头，跨域头由 CORS 按本次请求的 Origin 设置
+func skipReplayHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	return replaySkipHeaders[name] || strings.HasPrefix(name, "Access-Control-")
+}
+
+// replayableHeader 复制需要保存的响应头
+func replayableHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		if !skipReplayHeader(name) {
+			out[name] = append([]string(nil), values...)
+		}
+	}
+	return out
+}
+
+// captureWriter 在写出响应的同时保存完整响应体，用于幂等重放和响应缓存
+type captureWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+// Write 写出响应并保存
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString 写出响应并保存
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 保存响应体，超过 limit 后放弃
+func (w *captureWriter) capture(b []byte) {
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+len(b) > w.limit {
+		w.overflow = true
+		w.buf = bytes.Buffer{}
+		return
+	}
+	w.buf.Write(b)
+}
+
+// Idempotency 幂等键中间件，注册在需要认证的写操作路由上
+// 对携带 Idempotency-Key 的 POST/PUT/PATCH/DELETE 请求，按 用户+键 保存首次响应（状态码、响应头、响应体），
+// 重试时原样返回并设置 Idempotent-Replayed: true；同一个键的请求正在处理时返回409，请求内容不同时返回422
+// 5xx响应、errors.IsRetryable 的响应码和panic不保存，客户端可以用同一个键重试；请求体超过 idempotency.max_body_bytes 时返回413
+// 处理中的记录占用 idempotency.lock_timeout 秒，实例崩溃后超时即可重新执行；记录保留 idempotency.ttl 秒，存储由 idempotency.store 决定
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isUnsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.BadRequest(c, "Idempotency-Key 长度不能超过255")
+			c.Abort()
+			return
+		}
+		scope, ok := callerIdentity(c.Request.Context())
+		if !ok {
+			// 未认证的请求无法区分调用方，不做幂等处理
+			c.Next()
+			return
+		}
+
+		cfg := config.Get().Idempotency
+		var body []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			if c.Request.ContentLength > int64(cfg.MaxBodyBytes) {
+				response.RequestEntityTooLarge(c, "请求体过大")
+				c.Abort()
+				return
+			}
+			var err error
+			if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, int64(cfg.MaxBodyBytes))); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					response.RequestEntityTooLarge(c, "请求体过大")
+				} else {
+					response.BadRequest(c, "读取请求体失败")
+				}
+				c.Abort()
+				return
+			}
+			c.Request.Body = readCloser{bytes.NewReader(body), c.Request.Body}
+		}
+
+		ctx := c.Request.Context()
+		// 请求结束后保存或释放记录不受客户端断开影响
+		storeCtx := context.WithoutCancel(ctx)
+		store := idempotency.GetStore()
+		storeKey := hashParts(scope, key)
+		fingerprint := hashParts(c.Request.Method, c.Request.URL.RequestURI(), string(body))
+
+		record, lease, err := store.Begin(ctx, storeKey, fingerprint, cfg.GetLockTimeout(), cfg.GetTTL())
+		if err != nil {
+			logger.ErrorContext(ctx, "读取幂等键失败", logger.Err(err))
+			response.InternalServerError(c, "服务器内部错误")
+			c.Abort()
+			return
+		}
+		if record != nil {
+			switch {
+			case record.Fingerprint != fingerprint:
+				response.UnprocessableEntity(c, "Idempotency-Key 已用于不同的请求")
+			case !record.Completed():
+				c.Header("Retry-After", "1")
+				response.Conflict(c, "相同 Idempotency-Key 的请求正在处理")
+			default:
+				replayResponse(c, record.Response)
+			}
+			c.Abort()
+			return
+		}
+
+		writer := &captureWriter{ResponseWriter: c.Writer, limit: maxIdempotentResponseBytes}
+		c.Writer = writer
+		completed := false
+		defer func() {
+			// 5xx、服务端故障的响应码、响应过大、保存失败或panic时释放，panic会继续传给 Recovery
+			if completed {
+				return
+			}
+			if err := store.Release(storeCtx, storeKey, lease); err != nil {
+				logger.WarnContext(ctx, "释放幂等键失败", logger.Err(err))
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || writer.overflow {
+			return
+		}
+		// response.Fail 的HTTP状态码为200，按响应码判断是否为服务端故障
+		if code, ok := response.GetCode(c); ok && apperrors.IsRetryable(code) {
+			return
+		}
+		resp := &idempotency.Response{
+			Status: status,
+			Header: replayableHeader(writer.Header()),
+			Body:   writer.buf.Bytes(),
+		}
+		if err := store.Complete(storeCtx, storeKey, lease, resp); err != nil {
+			logger.WarnContext(ctx, "保存幂等响应失败", logger.Err(err))
+			return
+		}
+		completed = true
+	}
+}
+
+// isUnsafeMethod 是否为会修改数据的请求方法
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// callerIdentity 已认证调用方的身份，用于隔离不同调用方的幂等键和缓存
+func callerIdentity(ctx context.Context) (string, bool) {
+	if userID, ok := reqctx.GetUserID(ctx); ok {
+		return "user:" + strconv.FormatUint(uint64(userID), 10), true
+	}
+	if principal := reqctx.GetPrincipal(ctx); principal != "" {
+		return "principal:" + principal, true
+	}
+	return "", false
+}
+
+// hashParts 计算各部分的SHA-256摘要，各部分之间以0字节分隔
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replayResponse 写出保存的响应，保留本次请求的 X-Request-ID 和跨域头
+func replayResponse(c *gin.Context, resp *idempotency.Response) {
+	header := c.Writer.Header()
+	for name, values := range resp.Header {
+		if skipReplayHeader(name) {
+			continue
+		}
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+	c.Writer.WriteHeader(resp.Status)
+	_, _ = c.Writer.Write(resp.Body)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/pkg/metrics"
+)
+
+// unmatchedRoute 未匹配路由的请求统一使用的标签值，避免原始路径导致标签数量无限增长
+const unmatchedRoute = "unmatched"
+
+// Metrics HTTP指标中间件，按路由模板、方法和状态码记录请求数和耗时
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		inFlight := metrics.HTTPInFlight()
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTP(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(start))
+	}
+}
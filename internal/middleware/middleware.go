@@ -2,82 +2,28 @@
 package middleware
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
 	"ruleback/pkg/errors"
+	"ruleback/pkg/jwt"
 	"ruleback/pkg/logger"
+	"ruleback/pkg/reqctx"
 	"ruleback/pkg/response"
 )
 
-// Logger 请求日志中间件
-func Logger() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
-		method := c.Request.Method
-		clientIP := c.ClientIP()
-
-		c.Next()
-
-		latency := time.Since(start)
-		statusCode := c.Writer.Status()
-
-		if query != "" {
-			path = path + "?" + query
-		}
+// RoleAdmin 管理员角色，用于变更历史等管理接口
+const RoleAdmin = "admin"
 
-		logger.Info("HTTP请求",
-			logger.String("method", method),
-			logger.String("path", path),
-			logger.String("ip", clientIP),
-			logger.Int("status", statusCode),
-			logger.Float64("latency_ms", float64(latency.Milliseconds())),
-		)
-	}
-}
-
-// Recovery 错误恢复中间件
-func Recovery() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		defer func() {
-			if err := recover(); err != nil {
-				logger.Error("服务器内部错误",
-					logger.Field("error", err),
-					logger.String("path", c.Request.URL.Path),
-					logger.String("method", c.Request.Method),
-				)
-				response.InternalServerError(c, "服务器内部错误")
-				c.Abort()
-			}
-		}()
-		c.Next()
+// Auth JWT认证中间件，校验 Authorization: Bearer <token>（HS256，密钥为 jwt.secret）
+// 校验通过后写入Token中的用户ID和角色，RequireRole 据此校验权限；
+// 未配置 jwt 时只检查是否携带Token，不写入身份，RequireRole 拒绝所有请求
+func Auth() gin.HandlerFunc {
+	if cfg := config.Get(); cfg == nil || cfg.JWT == nil {
+		logger.Warn("未配置 jwt，Auth 不校验Token，RequireRole 将拒绝所有请求")
 	}
-}
 
-// CORS 跨域处理中间件
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Max-Age", "86400")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// Auth JWT认证中间件
-func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
@@ -90,24 +36,54 @@ func Auth() gin.HandlerFunc {
 			token = token[7:]
 		}
 
-		// TODO: 验证JWT Token
-		// claims, err := jwt.ParseToken(token)
-		// if err != nil {
-		//     response.Unauthorized(c, "Token无效或已过期")
-		//     c.Abort()
-		//     return
-		// }
-		// c.Set("user_id", claims.UserID)
+		cfg := config.Get()
+		if cfg == nil || cfg.JWT == nil {
+			c.Next()
+			return
+		}
+		claims, err := jwt.ParseToken(token, cfg.JWT.Secret)
+		if err != nil || (cfg.JWT.Issuer != "" && claims.Issuer != cfg.JWT.Issuer) {
+			response.Unauthorized(c, "Token无效或已过期")
+			c.Abort()
+			return
+		}
+		if claims.UserID != 0 {
+			SetUserID(c, claims.UserID)
+		}
+		SetRoles(c, claims.Roles...)
 
 		c.Next()
 	}
 }
 
-// RequireRole 角色权限中间件
+// SetUserID 设置当前认证用户ID
+// 同时写入gin上下文和请求context，Service/Repository层可通过 reqctx.GetUserID 读取
+func SetUserID(c *gin.Context, userID uint) {
+	c.Set("user_id", userID)
+	c.Request = c.Request.WithContext(reqctx.WithUserID(c.Request.Context(), userID))
+}
+
+// SetRoles 设置当前认证用户的角色，RequireRole 据此校验权限
+// 同时写入gin上下文和请求context，Service层可通过 reqctx.GetRoles 读取
+func SetRoles(c *gin.Context, roles ...string) {
+	c.Set("roles", roles)
+	c.Request = c.Request.WithContext(reqctx.WithRoles(c.Request.Context(), roles))
+}
+
+// RequireRole 角色权限中间件，注册在认证中间件之后
+// 当前用户拥有任一角色时放行，否则返回403；认证中间件未调用 SetRoles 时一律拒绝（不再默认放行）
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: 从上下文获取用户角色并验证
-		c.Next()
+		for _, have := range reqctx.GetRoles(c.Request.Context()) {
+			for _, want := range roles {
+				if have == want {
+					c.Next()
+					return
+				}
+			}
+		}
+		response.Forbidden(c, "权限不足")
+		c.Abort()
 	}
 }
 
@@ -120,6 +96,7 @@ func RequestID() gin.HandlerFunc {
 		}
 
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(reqctx.WithRequestID(c.Request.Context(), requestID))
 		c.Header("X-Request-ID", requestID)
 
 		c.Next()
@@ -132,14 +109,6 @@ func generateRequestID() string {
 	return time.Now().Format("20060102150405.000000")
 }
 
-// RateLimit 请求限流中间件
-func RateLimit(limit int, window int) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// TODO: 实现基于Redis或内存的限流
-		c.Next()
-	}
-}
-
 // Timeout 请求超时中间件
 func Timeout(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -162,7 +131,7 @@ func ErrorHandler() gin.HandlerFunc {
 				return
 			}
 
-			logger.Error("未处理的错误", logger.Err(err))
+			logger.ErrorContext(c.Request.Context(), "未处理的错误", logger.Err(err))
 			response.InternalServerError(c, "服务器内部错误")
 		}
 	}
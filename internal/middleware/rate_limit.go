@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
+	"ruleback/pkg/response"
+)
+
+// rateLimiter 按客户端IP计数的令牌桶，每个桶容量为 capacity，每个窗口补满一次
+type rateLimiter struct {
+	settings  config.RateLimitConfig // 创建时的限流配置，配置变化时重建
+	enabled   bool
+	capacity  float64
+	perSecond float64
+	window    time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket 单个客户端的令牌桶
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimit 请求限流中间件，每个客户端IP在 window 秒内最多 limit 个请求（允许短时突发），超过时返回429和 Retry-After
+// rate_limit.enabled 为false时直接放行；rate_limit.requests/rate_limit.window 大于0时覆盖参数，支持热更新
+func RateLimit(limit int, window int) gin.HandlerFunc {
+	var current atomic.Pointer[rateLimiter]
+
+	return func(c *gin.Context) {
+		cfg := config.Get()
+		if cfg == nil || !cfg.RateLimit.Enabled {
+			c.Next()
+			return
+		}
+
+		limiter := current.Load()
+		if limiter == nil || limiter.settings != cfg.RateLimit {
+			fresh := newRateLimiter(cfg.RateLimit, limit, window)
+			if !current.CompareAndSwap(limiter, fresh) {
+				fresh = current.Load()
+			}
+			limiter = fresh
+		}
+		if !limiter.enabled {
+			c.Next()
+			return
+		}
+
+		if wait, allowed := limiter.allow(c.ClientIP(), time.Now()); !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			response.TooManyRequests(c, "请求过于频繁")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// newRateLimiter 创建限流器，配置中大于0的限额覆盖中间件参数
+func newRateLimiter(settings config.RateLimitConfig, limit, window int) *rateLimiter {
+	l := &rateLimiter{settings: settings, buckets: make(map[string]*tokenBucket)}
+	if settings.Requests > 0 {
+		limit = settings.Requests
+	}
+	if settings.Window > 0 {
+		window = settings.Window
+	}
+	if limit <= 0 || window <= 0 {
+		return l
+	}
+	l.enabled = true
+	l.capacity = float64(limit)
+	l.window = time.Duration(window) * time.Second
+	l.perSecond = l.capacity / l.window.Seconds()
+	return l
+}
+
+// allow 消耗一个令牌，令牌不足时返回需要等待的时间
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.capacity, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.capacity, b.tokens+now.Sub(b.updated).Seconds()*l.perSecond)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second)), false
+}
+
+// sweep 每个窗口清理一次超过一个窗口未访问的桶，这些桶已补满，删除后与新建等价
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= l.window {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/reqctx"
+	"ruleback/pkg/response"
+)
+
+const (
+	// maxStackFrames 记录的最大堆栈帧数
+	maxStackFrames = 32
+	// statusClientClosedRequest 客户端已断开时记录的状态码（nginx约定），只用于日志和指标
+	statusClientClosedRequest = 499
+)
+
+// StackFrame 堆栈帧
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// PanicReport 一次panic的完整信息，传给 PanicReporter
+type PanicReport struct {
+	Err       error        // panic值，非error类型时转换为error
+	Value     interface{}  // 原始panic值
+	Stack     []StackFrame // 从panic位置开始，已去掉运行时和框架内部的帧
+	RequestID string
+	Method    string
+	Path      string
+	Route     string // 路由模板，未匹配时为空
+	UserID    uint   // 未认证时为0
+	Written   bool   // panic前响应头是否已写出
+}
+
+// PanicReporter panic上报接口，用于接入Sentry等错误追踪服务
+// ReportPanic 在请求goroutine中同步调用，耗时操作应异步处理
+type PanicReporter interface {
+	ReportPanic(ctx context.Context, report *PanicReport)
+}
+
+// PanicReporterFunc 函数形式的 PanicReporter
+type PanicReporterFunc func(ctx context.Context, report *PanicReport)
+
+// ReportPanic 实现 PanicReporter
+func (f PanicReporterFunc) ReportPanic(ctx context.Context, report *PanicReport) {
+	f(ctx, report)
+}
+
+var (
+	reporters   []PanicReporter
+	reportersMu sync.RWMutex
+)
+
+// RegisterPanicReporter 注册panic上报器，可注册多个，按注册顺序调用
+// 在模块的 OnStart 或启动流程中注册，客户端断开导致的panic不会上报
+func RegisterPanicReporter(r PanicReporter) {
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	reporters = append(reporters, r)
+}
+
+// Recovery 错误恢复中间件
+// 记录panic值、结构化堆栈、请求ID和路由，并调用已注册的 PanicReporter
+// 客户端已断开时只记录警告；响应头已写出时不再写入错误响应；app.debug 为true时响应中包含堆栈
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				// 约定的中止信号，交给 net/http 静默断开连接
+				panic(value)
+			}
+			handlePanic(c, value)
+		}()
+		c.Next()
+	}
+}
+
+// handlePanic 记录、上报panic并写入错误响应
+func handlePanic(c *gin.Context, value interface{}) {
+	ctx := c.Request.Context()
+	err, ok := value.(error)
+	if !ok {
+		err = fmt.Errorf("%v", value)
+	}
+	_ = c.Error(err)
+
+	if isClientGone(ctx, err) {
+		logger.WarnContext(ctx, "客户端已断开连接",
+			logger.Err(err),
+			logger.String("method", c.Request.Method),
+			logger.String("path", c.Request.URL.Path),
+		)
+		c.Status(statusClientClosedRequest)
+		c.Abort()
+		return
+	}
+
+	report := &PanicReport{
+		Err:       err,
+		Value:     value,
+		Stack:     captureStack(),
+		RequestID: reqctx.GetRequestID(ctx),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Route:     c.FullPath(),
+		Written:   c.Writer.Written(),
+	}
+	report.UserID, _ = reqctx.GetUserID(ctx)
+
+	logger.ErrorContext(ctx, "服务器内部错误",
+		logger.Field("panic", value),
+		logger.String("method", report.Method),
+		logger.String("path", report.Path),
+		logger.String("route", report.Route),
+		logger.Uint("user_id", report.UserID),
+		logger.Bool("response_written", report.Written),
+		logger.Field("stack", report.Stack),
+	)
+	reportPanic(ctx, report)
+
+	if report.Written {
+		// 状态码和部分响应体已发出，再写入会拼接出错误的响应
+		c.Abort()
+		return
+	}
+	if cfg := config.Get(); cfg != nil && cfg.App.Debug {
+		response.InternalServerErrorWithData(c, "服务器内部错误", gin.H{
+			"panic": err.Error(),
+			"stack": report.Stack,
+		})
+	} else {
+		response.InternalServerError(c, "服务器内部错误")
+	}
+	c.Abort()
+}
+
+// reportPanic 调用已注册的上报器，上报器自身的panic不影响响应
+func reportPanic(ctx context.Context, report *PanicReport) {
+	reportersMu.RLock()
+	list := reporters
+	reportersMu.RUnlock()
+
+	for _, r := range list {
+		func() {
+			defer func() {
+				if v := recover(); v != nil {
+					logger.ErrorContext(ctx, "panic上报失败", logger.Field("panic", v))
+				}
+			}()
+			r.ReportPanic(ctx, report)
+		}()
+	}
+}
+
+// isClientGone 判断panic是否由客户端断开连接引起（写响应时 broken pipe、connection reset，或请求已取消）
+func isClientGone(ctx context.Context, err error) bool {
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	return errors.Is(err, context.Canceled) && errors.Is(ctx.Err(), context.Canceled)
+}
+
+// captureStack 获取panic位置的调用栈
+// 跳过 runtime 和 gin 的内部帧，到 net/http 的服务器帧为止
+func captureStack() []StackFrame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []StackFrame
+	for len(stack) < maxStackFrames {
+		frame, more := frames.Next()
+		switch {
+		case strings.HasPrefix(frame.Function, "net/http."):
+			return stack
+		case strings.HasPrefix(frame.Function, "runtime."),
+			strings.HasPrefix(frame.Function, "github.com/gin-gonic/gin."),
+			strings.HasPrefix(frame.Function, "ruleback/internal/middleware.Recovery"),
+			strings.HasPrefix(frame.Function, "ruleback/internal/middleware.handlePanic"):
+		default:
+			stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditAction 变更类型
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditRedacted 脱敏字段在变更记录中的占位值
+const AuditRedacted = "******"
+
+// Auditable 需要记录变更历史的模型实现此接口，嵌入 AuditTrail 即可
+type Auditable interface {
+	AuditTrailEnabled() bool
+}
+
+// AuditTrail 变更历史开关，嵌入模型后该模型的增删改会写入 audit_logs
+// 字段标签 `audit:"-"` 表示不记录，`audit:"redact"` 表示记录变更但隐藏值
+type AuditTrail struct{}
+
+// AuditTrailEnabled 实现 Auditable 接口
+func (AuditTrail) AuditTrailEnabled() bool {
+	return true
+}
+
+// AuditLog 变更历史记录
+type AuditLog struct {
+	ID        uint         `gorm:"primarykey" json:"id"`
+	Table     string       `gorm:"column:table_name;type:varchar(64);index:idx_audit_record;not null" json:"table_name"`
+	RecordID  string       `gorm:"type:varchar(64);index:idx_audit_record;not null" json:"record_id"`
+	Action    AuditAction  `gorm:"type:varchar(16);not null" json:"action"`
+	Changes   AuditChanges `gorm:"type:text" json:"changes"`
+	ActorID   uint         `gorm:"index" json:"actor_id"`
+	RequestID string       `gorm:"type:varchar(64)" json:"request_id"`
+	CreatedAt time.Time    `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditFieldChange 单个字段的变更
+type AuditFieldChange struct {
+	Old interface{} `json:"old,omitempty"`
+	New interface{} `json:"new,omitempty"`
+}
+
+// AuditChanges 字段变更集合，键为数据库列名，以JSON文本存储
+type AuditChanges map[string]AuditFieldChange
+
+// Value 实现 driver.Valuer 接口
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan 实现 sql.Scanner 接口
+func (c *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为 AuditChanges", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// AuditLogQuery 变更历史查询参数
+type AuditLogQuery struct {
+	PageQuery
+	Table    string `form:"table" binding:"required"`
+	RecordID string `form:"record_id" binding:"required"`
+}
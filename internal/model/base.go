@@ -5,14 +5,16 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"ruleback/pkg/database"
+	"ruleback/pkg/idgen"
 )
 
 // BaseModel 基础模型，所有数据模型都应嵌入此结构体
 type BaseModel struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID        uint               `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	DeletedAt database.DeletedAt `gorm:"index" json:"-"`
 }
 
 // BaseModelWithoutSoftDelete 不带软删除的基础模型
@@ -22,6 +24,72 @@ type BaseModelWithoutSoftDelete struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UUIDModel 使用UUIDv7主键的基础模型，ID按时间有序且不暴露记录数量
+// 模型自定义 BeforeCreate 时需调用 m.UUIDModel.BeforeCreate(tx)
+type UUIDModel struct {
+	ID        string             `gorm:"type:char(36);primarykey" json:"id"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	DeletedAt database.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate 创建前生成UUIDv7主键
+func (m *UUIDModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = idgen.NewUUIDv7()
+	}
+	return nil
+}
+
+// ULIDModel 使用ULID主键的基础模型
+// 模型自定义 BeforeCreate 时需调用 m.ULIDModel.BeforeCreate(tx)
+type ULIDModel struct {
+	ID        string             `gorm:"type:char(26);primarykey" json:"id"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	DeletedAt database.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate 创建前生成ULID主键
+func (m *ULIDModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = idgen.NewULID()
+	}
+	return nil
+}
+
+// SnowflakeModel 使用Snowflake主键的基础模型，节点ID由 app.node_id 配置
+// JSON中以字符串输出，避免前端精度丢失
+// 模型自定义 BeforeCreate 时需调用 m.SnowflakeModel.BeforeCreate(tx)
+type SnowflakeModel struct {
+	ID        int64              `gorm:"primarykey;autoIncrement:false" json:"id,string"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	DeletedAt database.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate 创建前生成Snowflake主键
+func (m *SnowflakeModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == 0 {
+		m.ID = idgen.NewSnowflakeID()
+	}
+	return nil
+}
+
+// AuditModel 审计字段模型，记录创建人、更新人和删除人
+// 与 BaseModel 一同嵌入即可，字段由 database 包注册的GORM回调和 database.DeletedAt 从请求上下文自动填充
+type AuditModel struct {
+	CreatedBy uint `gorm:"index" json:"created_by"`
+	UpdatedBy uint `json:"updated_by"`
+	DeletedBy uint `json:"-"`
+}
+
+// VersionModel 乐观锁版本字段，嵌入后 BaseRepository.Update 和 UpdateWithVersion 按版本更新
+// 新记录版本为0，每次成功更新加1
+type VersionModel struct {
+	Version uint `gorm:"not null;default:0" json:"version"`
+}
+
 // Status 通用状态类型
 type Status int8
 
@@ -0,0 +1,47 @@
+package modules
+
+import (
+	"ruleback/internal/handler"
+	"ruleback/internal/middleware"
+	"ruleback/internal/model"
+	"ruleback/pkg/database"
+	"ruleback/pkg/module"
+)
+
+// AuditModule 变更历史模块
+type AuditModule struct {
+	module.Base
+	handler *handler.AuditLogHandler
+}
+
+// NewAuditModule 创建AuditModule实例
+func NewAuditModule(h *handler.AuditLogHandler) *AuditModule {
+	return &AuditModule{handler: h}
+}
+
+// Name 模块名称
+func (m *AuditModule) Name() string {
+	return "audit"
+}
+
+// DependsOn 依赖的模块
+func (m *AuditModule) DependsOn() []string {
+	return []string{databaseModule}
+}
+
+// Models 模块拥有的模型
+func (m *AuditModule) Models() []interface{} {
+	return []interface{}{&model.AuditLog{}}
+}
+
+// Routes 注册变更历史路由，仅管理员可查询
+func (m *AuditModule) Routes(r module.Routes) {
+	r.Authenticated.GET("/audit-logs", middleware.RequireRole(middleware.RoleAdmin), m.handler.History)
+}
+
+// Migrations 模块的版本化迁移，已发布的迁移不要修改或删除
+func (m *AuditModule) Migrations() []database.Migration {
+	return []database.Migration{
+		database.ModelMigration("20261018000000_create_audit_logs", &model.AuditLog{}),
+	}
+}
@@ -0,0 +1,47 @@
+package modules
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"ruleback/pkg/database"
+	"ruleback/pkg/module"
+)
+
+// databaseModule 数据库模块名称，使用数据库的模块在 DependsOn 中声明
+const databaseModule = "database"
+
+// DatabaseModule 数据库连接，其他使用数据库的模块应依赖此模块，确保连接最后关闭
+type DatabaseModule struct {
+	module.Base
+	db *gorm.DB
+}
+
+// NewDatabaseModule 创建DatabaseModule实例
+func NewDatabaseModule(db *gorm.DB) *DatabaseModule {
+	return &DatabaseModule{db: db}
+}
+
+// Name 模块名称
+func (m *DatabaseModule) Name() string {
+	return databaseModule
+}
+
+// OnStop 关闭数据库连接
+func (m *DatabaseModule) OnStop(ctx context.Context) error {
+	return database.Close()
+}
+
+// HealthChecks 检查数据库连接
+func (m *DatabaseModule) HealthChecks() []module.HealthCheck {
+	return []module.HealthCheck{{Name: "ping", Check: m.ping}}
+}
+
+// ping 测试数据库连接
+func (m *DatabaseModule) ping(ctx context.Context) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,30 @@
+package modules
+
+import (
+	"context"
+
+	"ruleback/internal/config"
+	"ruleback/pkg/httpcache"
+	"ruleback/pkg/module"
+)
+
+// HTTPCacheModule 服务端响应缓存存储，按 http_cache.max_entries 创建进程内缓存
+type HTTPCacheModule struct {
+	module.Base
+}
+
+// NewHTTPCacheModule 创建HTTPCacheModule实例
+func NewHTTPCacheModule() *HTTPCacheModule {
+	return &HTTPCacheModule{}
+}
+
+// Name 模块名称
+func (m *HTTPCacheModule) Name() string {
+	return "http_cache"
+}
+
+// OnStart 设置全局存储
+func (m *HTTPCacheModule) OnStart(ctx context.Context) error {
+	httpcache.SetStore(httpcache.NewMemoryStore(config.Get().HTTPCache.MaxEntries))
+	return nil
+}
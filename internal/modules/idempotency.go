@@ -0,0 +1,104 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"ruleback/internal/config"
+	"ruleback/pkg/database"
+	"ruleback/pkg/idempotency"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/module"
+)
+
+// idempotencyPurgeInterval 清理过期幂等键记录的间隔
+const idempotencyPurgeInterval = 10 * time.Minute
+
+// IdempotencyModule 幂等键存储，按 idempotency.store 选择存储并定期清理过期记录
+type IdempotencyModule struct {
+	module.Base
+	db   *gorm.DB
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewIdempotencyModule 创建IdempotencyModule实例
+func NewIdempotencyModule(db *gorm.DB) *IdempotencyModule {
+	return &IdempotencyModule{db: db}
+}
+
+// Name 模块名称
+func (m *IdempotencyModule) Name() string {
+	return "idempotency"
+}
+
+// DependsOn 依赖的模块
+func (m *IdempotencyModule) DependsOn() []string {
+	return []string{databaseModule}
+}
+
+// Models 模块拥有的模型
+func (m *IdempotencyModule) Models() []interface{} {
+	return []interface{}{&idempotency.KeyRecord{}}
+}
+
+// Migrations 模块的版本化迁移，已发布的迁移不要修改或删除
+func (m *IdempotencyModule) Migrations() []database.Migration {
+	return []database.Migration{
+		database.ModelMigration("20261018010000_create_idempotency_keys", &idempotency.KeyRecord{}),
+	}
+}
+
+// OnStart 设置全局存储并启动过期记录清理
+func (m *IdempotencyModule) OnStart(ctx context.Context) error {
+	var store idempotency.Store
+	if config.Get().Idempotency.Store == "memory" {
+		store = idempotency.NewMemoryStore()
+	} else {
+		store = idempotency.NewDatabaseStore(m.db)
+	}
+	idempotency.SetStore(store)
+
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.purgeLoop(store)
+	return nil
+}
+
+// OnStop 停止过期记录清理
+func (m *IdempotencyModule) OnStop(ctx context.Context) error {
+	if m.stop == nil {
+		return nil
+	}
+	close(m.stop)
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// purgeLoop 定期删除过期记录，过期记录在 Begin 时也会被覆盖，这里只用于回收空间
+func (m *IdempotencyModule) purgeLoop(store idempotency.Store) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(idempotencyPurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			n, err := store.Purge(context.Background(), time.Now())
+			if err != nil {
+				logger.Warn("清理过期幂等键失败", logger.Err(err))
+				continue
+			}
+			if n > 0 {
+				logger.Debug("已清理过期幂等键", logger.Int64("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Package modules 业务模块注册
+package modules
+
+import (
+	"gorm.io/gorm"
+	"ruleback/internal/wire"
+	"ruleback/pkg/module"
+)
+
+// All 返回所有模块
+// 启动顺序由 DependsOn 决定，无依赖关系时按此列表顺序；关闭顺序相反
+func All(db *gorm.DB, handlers *wire.Handlers) []module.Module {
+	return []module.Module{
+		NewDatabaseModule(db),
+		NewAuditModule(handlers.AuditLogHandler),
+		NewIdempotencyModule(db),
+		NewHTTPCacheModule(),
+		// 在此添加你的模块
+	}
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"ruleback/internal/model"
+)
+
+// AuditLogRepository 变更历史数据访问层
+type AuditLogRepository struct {
+	*BaseRepository
+}
+
+// NewAuditLogRepository 创建AuditLogRepository实例（用于Wire依赖注入）
+func NewAuditLogRepository(base *BaseRepository) *AuditLogRepository {
+	return &AuditLogRepository{BaseRepository: base}
+}
+
+// IsAuditedTable 表是否记录变更历史
+func (r *AuditLogRepository) IsAuditedTable(table string) bool {
+	return isAuditedTable(table)
+}
+
+// ListByRecord 获取指定记录的变更历史，按时间倒序
+func (r *AuditLogRepository) ListByRecord(ctx context.Context, query *model.AuditLogQuery) ([]*model.AuditLog, int64, error) {
+	var logs []*model.AuditLog
+	var total int64
+
+	db := r.DBWithContext(ctx).Model(&model.AuditLog{}).
+		Where("table_name = ? AND record_id = ?", query.Table, query.RecordID)
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	db = db.Order("id desc").Scopes(r.Paginate(query.Page, query.PageSize))
+
+	if err := db.Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
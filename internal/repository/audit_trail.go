@@ -0,0 +1,312 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"ruleback/internal/model"
+	"ruleback/pkg/database"
+	"ruleback/pkg/reqctx"
+)
+
+// auditSnapshotKey 更新/删除前的记录快照在Statement中的键
+const auditSnapshotKey = "audit_trail:snapshot"
+
+// auditRecord 单条记录的快照
+type auditRecord struct {
+	primaryKey interface{}
+	values     map[string]interface{}
+}
+
+// auditSnapshot 记录快照，键为记录ID
+type auditSnapshot map[string]auditRecord
+
+var (
+	auditedTablesMu sync.RWMutex
+	auditedTables   = make(map[string]struct{})
+)
+
+// RegisterAuditTrail 注册变更历史回调
+// 嵌入 model.AuditTrail 的模型在增删改时，会在同一事务内写入 audit_logs
+func RegisterAuditTrail(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:after_create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit_trail:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:before_update").Before("gorm:update").
+		Register("audit_trail:before_update", auditCaptureSnapshot); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:after_update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit_trail:after_update", auditAfterUpdate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:before_delete").Before("gorm:delete").
+		Register("audit_trail:before_delete", auditCaptureSnapshot); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:after_delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit_trail:after_delete", auditAfterDelete)
+}
+
+// RegisterAuditedModels 登记嵌入 model.AuditTrail 的模型对应的表，变更历史接口只允许查询已登记的表
+// 未嵌入 model.AuditTrail 的模型会被忽略，启动时传入所有模块的模型即可
+func RegisterAuditedModels(db *gorm.DB, models ...interface{}) error {
+	auditedTablesMu.Lock()
+	defer auditedTablesMu.Unlock()
+
+	for _, m := range models {
+		auditable, ok := m.(model.Auditable)
+		if !ok || !auditable.AuditTrailEnabled() {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return fmt.Errorf("解析模型 %T 失败: %w", m, err)
+		}
+		auditedTables[stmt.Schema.Table] = struct{}{}
+	}
+	return nil
+}
+
+// isAuditedTable 表是否已通过 RegisterAuditedModels 登记
+func isAuditedTable(table string) bool {
+	auditedTablesMu.RLock()
+	defer auditedTablesMu.RUnlock()
+	_, ok := auditedTables[table]
+	return ok
+}
+
+// auditAfterCreate 记录新建的记录
+func auditAfterCreate(db *gorm.DB) {
+	if !auditEnabled(db) {
+		return
+	}
+
+	ctx := db.Statement.Context
+	var logs []*model.AuditLog
+	eachStructValue(db.Statement.ReflectValue, func(rv reflect.Value) {
+		values := snapshotValues(ctx, db.Statement.Schema, rv)
+		changes := make(model.AuditChanges, len(values))
+		for column, value := range values {
+			changes[column] = newFieldChange(db.Statement.Schema, column, nil, value)
+		}
+		id, _ := recordID(ctx, db.Statement.Schema, rv)
+		logs = append(logs, newAuditLog(db, id, model.AuditActionCreate, changes))
+	})
+
+	writeAuditLogs(db, logs)
+}
+
+// auditAfterUpdate 对比更新前后的快照，记录发生变化的字段
+func auditAfterUpdate(db *gorm.DB) {
+	if !auditEnabled(db) || db.RowsAffected == 0 {
+		return
+	}
+	before, ok := loadedSnapshot(db)
+	if !ok || len(before) == 0 {
+		return
+	}
+
+	primaryKeys := make([]interface{}, 0, len(before))
+	for _, record := range before {
+		primaryKeys = append(primaryKeys, record.primaryKey)
+	}
+	after := querySnapshot(db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(clause.IN{
+			Column: clause.Column{Table: clause.CurrentTable, Name: db.Statement.Schema.PrioritizedPrimaryField.DBName},
+			Values: primaryKeys,
+		})
+	})
+
+	var logs []*model.AuditLog
+	for id, oldRecord := range before {
+		newRecord, ok := after[id]
+		if !ok {
+			continue
+		}
+		changes := make(model.AuditChanges)
+		for column, oldValue := range oldRecord.values {
+			if newValue := newRecord.values[column]; !reflect.DeepEqual(oldValue, newValue) {
+				changes[column] = newFieldChange(db.Statement.Schema, column, oldValue, newValue)
+			}
+		}
+		if len(changes) > 0 {
+			logs = append(logs, newAuditLog(db, id, model.AuditActionUpdate, changes))
+		}
+	}
+
+	writeAuditLogs(db, logs)
+}
+
+// auditAfterDelete 记录被删除的记录
+func auditAfterDelete(db *gorm.DB) {
+	if !auditEnabled(db) || db.RowsAffected == 0 {
+		return
+	}
+	before, ok := loadedSnapshot(db)
+	if !ok {
+		return
+	}
+
+	logs := make([]*model.AuditLog, 0, len(before))
+	for id, record := range before {
+		changes := make(model.AuditChanges, len(record.values))
+		for column, value := range record.values {
+			changes[column] = newFieldChange(db.Statement.Schema, column, value, nil)
+		}
+		logs = append(logs, newAuditLog(db, id, model.AuditActionDelete, changes))
+	}
+
+	writeAuditLogs(db, logs)
+}
+
+// auditCaptureSnapshot 在更新/删除执行前按相同条件查询受影响的记录
+func auditCaptureSnapshot(db *gorm.DB) {
+	if !auditEnabled(db) {
+		return
+	}
+
+	stmt := db.Statement
+	stmt.Settings.Delete(auditSnapshotKey)
+
+	var conds []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conds = append(conds, where.Exprs...)
+		}
+	}
+	_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	if len(values) > 0 {
+		conds = append(conds, clause.IN{Column: column, Values: values})
+	}
+	// 与GORM一致，没有条件的全表更新/删除不做快照
+	if len(conds) == 0 {
+		return
+	}
+
+	snapshot := querySnapshot(db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Clauses(clause.Where{Exprs: conds})
+	})
+	stmt.Settings.Store(auditSnapshotKey, snapshot)
+}
+
+// auditEnabled 判断当前语句的模型是否开启变更历史
+func auditEnabled(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return false
+	}
+	auditable, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(model.Auditable)
+	return ok && auditable.AuditTrailEnabled()
+}
+
+// loadedSnapshot 读取执行前保存的快照
+func loadedSnapshot(db *gorm.DB) (auditSnapshot, bool) {
+	v, ok := db.Statement.Settings.LoadAndDelete(auditSnapshotKey)
+	if !ok {
+		return nil, false
+	}
+	snapshot, ok := v.(auditSnapshot)
+	return snapshot, ok
+}
+
+// querySnapshot 在当前连接（含事务）上查询记录并生成快照
+func querySnapshot(db *gorm.DB, scope func(tx *gorm.DB) *gorm.DB) auditSnapshot {
+	stmt := db.Statement
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Table(stmt.Table)
+	if stmt.Unscoped {
+		tx = tx.Unscoped()
+	}
+	if err := scope(tx).Find(rows.Interface()).Error; err != nil {
+		_ = db.AddError(fmt.Errorf("查询变更历史快照失败: %w", err))
+		return nil
+	}
+
+	snapshot := make(auditSnapshot)
+	eachStructValue(rows.Elem(), func(rv reflect.Value) {
+		id, primaryKey := recordID(stmt.Context, stmt.Schema, rv)
+		snapshot[id] = auditRecord{primaryKey: primaryKey, values: snapshotValues(stmt.Context, stmt.Schema, rv)}
+	})
+	return snapshot
+}
+
+// snapshotValues 读取记录中需要审计的字段值
+// 跳过自动维护的时间字段、软删除字段以及 audit:"-" 字段
+func snapshotValues(ctx context.Context, s *schema.Schema, rv reflect.Value) map[string]interface{} {
+	values := make(map[string]interface{}, len(s.Fields))
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 ||
+			field.FieldType == database.DeletedAtType || field.Tag.Get("audit") == "-" {
+			continue
+		}
+		value, _ := field.ValueOf(ctx, rv)
+		values[field.DBName] = value
+	}
+	return values
+}
+
+// newFieldChange 创建字段变更，audit:"redact" 字段只记录发生了变化，值以占位符代替
+func newFieldChange(s *schema.Schema, column string, oldValue, newValue interface{}) model.AuditFieldChange {
+	if field := s.LookUpField(column); field != nil && field.Tag.Get("audit") == "redact" {
+		if oldValue != nil {
+			oldValue = model.AuditRedacted
+		}
+		if newValue != nil {
+			newValue = model.AuditRedacted
+		}
+	}
+	return model.AuditFieldChange{Old: oldValue, New: newValue}
+}
+
+// recordID 获取记录主键的字符串形式和原始值
+func recordID(ctx context.Context, s *schema.Schema, rv reflect.Value) (string, interface{}) {
+	value, _ := s.PrioritizedPrimaryField.ValueOf(ctx, rv)
+	return fmt.Sprint(value), value
+}
+
+// newAuditLog 创建变更记录，操作人和请求ID取自语句context
+func newAuditLog(db *gorm.DB, id string, action model.AuditAction, changes model.AuditChanges) *model.AuditLog {
+	ctx := db.Statement.Context
+	actorID, _ := reqctx.GetUserID(ctx)
+	return &model.AuditLog{
+		Table:     db.Statement.Table,
+		RecordID:  id,
+		Action:    action,
+		Changes:   changes,
+		ActorID:   actorID,
+		RequestID: reqctx.GetRequestID(ctx),
+	}
+}
+
+// writeAuditLogs 在当前连接（含事务）上写入变更记录，失败时使原操作失败
+func writeAuditLogs(db *gorm.DB, logs []*model.AuditLog) {
+	if len(logs) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true, SkipHooks: true}).Create(&logs).Error; err != nil {
+		_ = db.AddError(fmt.Errorf("写入变更历史失败: %w", err))
+	}
+}
+
+// eachStructValue 遍历单条或批量记录
+func eachStructValue(rv reflect.Value, fn func(rv reflect.Value)) {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if elem := reflect.Indirect(rv.Index(i)); elem.Kind() == reflect.Struct {
+				fn(elem)
+			}
+		}
+	case reflect.Struct:
+		fn(rv)
+	}
+}
@@ -2,9 +2,12 @@
 package repository
 
 import (
+	"context"
+	"fmt"
 	"sync"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"ruleback/pkg/database"
 )
 
@@ -34,33 +37,59 @@ func GetBaseRepository() *BaseRepository {
 }
 
 // DB 获取数据库实例
+//
+// Deprecated: 返回的连接不会加入ctx中的事务，也不携带ctx的取消和链路追踪，请使用 DBWithContext
 func (r *BaseRepository) DB() *gorm.DB {
 	return r.db
 }
 
+// DBWithContext 获取绑定context的数据库实例
+// ctx中存在 TxManager 开启的事务时返回该事务，否则返回普通连接
+func (r *BaseRepository) DBWithContext(ctx context.Context) *gorm.DB {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
+}
+
 // Create 创建记录
-func (r *BaseRepository) Create(model interface{}) error {
-	return r.db.Create(model).Error
+func (r *BaseRepository) Create(ctx context.Context, model interface{}) error {
+	return r.DBWithContext(ctx).Create(model).Error
 }
 
 // Update 更新记录
-func (r *BaseRepository) Update(model interface{}) error {
-	return r.db.Save(model).Error
+// 模型嵌入 model.VersionModel 时按乐观锁更新（同 UpdateWithVersion），否则保存全部字段
+func (r *BaseRepository) Update(ctx context.Context, model interface{}) error {
+	db := r.DBWithContext(ctx)
+	if hasVersionField(db, model) {
+		return r.UpdateWithVersion(ctx, model)
+	}
+	return db.Save(model).Error
 }
 
 // Delete 删除记录（软删除）
-func (r *BaseRepository) Delete(model interface{}) error {
-	return r.db.Delete(model).Error
+func (r *BaseRepository) Delete(ctx context.Context, model interface{}) error {
+	return r.DBWithContext(ctx).Delete(model).Error
 }
 
-// DeleteByID 根据ID删除记录
-func (r *BaseRepository) DeleteByID(model interface{}, id uint) error {
-	return r.db.Delete(model, id).Error
+// DeleteByID 根据ID删除记录，id 可以是 uint、int64（Snowflake）或 string（UUID/ULID）
+func (r *BaseRepository) DeleteByID(ctx context.Context, model interface{}, id interface{}) error {
+	db := r.DBWithContext(ctx)
+	cond, err := primaryKeyEq(db, model, id)
+	if err != nil {
+		return err
+	}
+	return db.Where(cond).Delete(model).Error
 }
 
-// GetByID 根据ID获取记录
-func (r *BaseRepository) GetByID(model interface{}, id uint) error {
-	return r.db.First(model, id).Error
+// GetByID 根据ID获取记录，id 可以是 uint、int64（Snowflake）或 string（UUID/ULID）
+func (r *BaseRepository) GetByID(ctx context.Context, model interface{}, id interface{}) error {
+	db := r.DBWithContext(ctx)
+	cond, err := primaryKeyEq(db, model, id)
+	if err != nil {
+		return err
+	}
+	return db.Where(cond).First(model).Error
 }
 
 // Paginate 分页查询
@@ -93,7 +122,24 @@ func (r *BaseRepository) OrderBy(field, order string) func(*gorm.DB) *gorm.DB {
 	}
 }
 
+// primaryKeyEq 构建主键等值条件
+// 不直接使用 First(model, id)，因为GORM会把非数字字符串当作SQL条件
+func primaryKeyEq(db *gorm.DB, model interface{}, id interface{}) (clause.Expression, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, fmt.Errorf("模型 %s 缺少主键", stmt.Schema.Name)
+	}
+	return clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: stmt.Schema.PrioritizedPrimaryField.DBName},
+		Value:  id,
+	}, nil
+}
+
 // Transaction 执行事务
+// 跨Repository共享事务请使用 TxManager.Transaction
 func (r *BaseRepository) Transaction(fn func(tx *gorm.DB) error) error {
 	return r.db.Transaction(fn)
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	apperrors "ruleback/pkg/errors"
+)
+
+// versionField 乐观锁版本字段名，与 model.VersionModel 保持一致
+const versionField = "Version"
+
+// UpdateWithVersion 使用乐观锁保存记录（模型需嵌入 model.VersionModel）
+// 仅当数据库中的版本与模型当前版本一致时更新，成功后模型版本号加1
+// 版本不一致时返回错误码为 CodeConflict 的 *errors.AppError（可用 errors.GetCurrentVersion 取当前版本），
+// 记录不存在时返回 gorm.ErrRecordNotFound
+func (r *BaseRepository) UpdateWithVersion(ctx context.Context, value interface{}) error {
+	db := r.DBWithContext(ctx)
+
+	s, field, err := parseVersionField(db, value)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	fieldValue, _ := field.ValueOf(ctx, rv)
+	version, ok := fieldValue.(uint)
+	if !ok {
+		return fmt.Errorf("模型 %s 的版本字段类型必须为 uint", s.Name)
+	}
+
+	if err := field.Set(ctx, rv, version+1); err != nil {
+		return err
+	}
+
+	result := db.Model(value).Select("*").
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: version}).
+		Updates(value)
+	if result.Error == nil && result.RowsAffected > 0 {
+		return nil
+	}
+
+	_ = field.Set(ctx, rv, version)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	primaryKey, _ := s.PrioritizedPrimaryField.ValueOf(ctx, rv)
+	return r.versionConflict(ctx, s, field, primaryKey)
+}
+
+// UpdateFieldsWithVersion 使用乐观锁更新指定字段
+// 仅当数据库中的版本等于 version 时更新，同时版本号加1；错误同 UpdateWithVersion
+func (r *BaseRepository) UpdateFieldsWithVersion(ctx context.Context, value interface{}, id interface{}, version uint, fields map[string]interface{}) error {
+	db := r.DBWithContext(ctx)
+
+	s, field, err := parseVersionField(db, value)
+	if err != nil {
+		return err
+	}
+
+	updates := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		updates[k] = v
+	}
+	updates[field.DBName] = gorm.Expr(field.DBName+" + ?", 1)
+
+	result := db.Model(value).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: s.PrioritizedPrimaryField.DBName}, Value: id}).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: version}).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.versionConflict(ctx, s, field, id)
+	}
+	return nil
+}
+
+// versionConflict 查询记录当前版本并生成版本冲突错误
+func (r *BaseRepository) versionConflict(ctx context.Context, s *schema.Schema, field *schema.Field, primaryKey interface{}) error {
+	latest := reflect.New(s.ModelType)
+	err := r.DBWithContext(ctx).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: s.PrioritizedPrimaryField.DBName}, Value: primaryKey}).
+		Take(latest.Interface()).Error
+	if err != nil {
+		return err
+	}
+
+	current, _ := field.ValueOf(ctx, latest.Elem())
+	currentVersion, _ := current.(uint)
+	return apperrors.NewVersionConflict(currentVersion)
+}
+
+// hasVersionField 模型是否嵌入 model.VersionModel
+func hasVersionField(db *gorm.DB, value interface{}) bool {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return false
+	}
+	return stmt.Schema.LookUpField(versionField) != nil
+}
+
+// parseVersionField 解析模型并查找版本字段
+func parseVersionField(db *gorm.DB, value interface{}) (*schema.Schema, *schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return nil, nil, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, nil, fmt.Errorf("模型 %s 缺少主键", stmt.Schema.Name)
+	}
+
+	field := stmt.Schema.LookUpField(versionField)
+	if field == nil {
+		return nil, nil, fmt.Errorf("模型 %s 未嵌入 VersionModel", stmt.Schema.Name)
+	}
+	return stmt.Schema, field, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+	"ruleback/pkg/logger"
+)
+
+// txContextKey 事务在context中的键
+type txContextKey struct{}
+
+// txState 事务状态，记录事务句柄和提交后回调
+type txState struct {
+	tx     *gorm.DB
+	parent *txState
+	mu     sync.Mutex
+	hooks  []func(ctx context.Context)
+}
+
+// addHook 添加提交后回调
+func (s *txState) addHook(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, fn)
+}
+
+// takeHooks 取出并清空提交后回调
+func (s *txState) takeHooks() []func(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hooks := s.hooks
+	s.hooks = nil
+	return hooks
+}
+
+// TxManager 基于context传播的事务管理器
+// 事务存放在context中，所有Repository通过 DBWithContext(ctx) 自动加入当前事务
+type TxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager 创建TxManager实例（用于依赖注入）
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// Transaction 在事务中执行fn
+// 若ctx中已存在事务，则以保存点（SAVEPOINT）方式嵌套执行，fn返回错误时仅回滚到保存点
+// 最外层事务提交成功后，依次执行通过 AfterCommit 注册的回调
+func (m *TxManager) Transaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*sql.TxOptions) error {
+	parent := txStateFromContext(ctx)
+
+	db := m.db
+	if parent != nil {
+		db = parent.tx
+	}
+
+	state := &txState{parent: parent}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txContextKey{}, state))
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	hooks := state.takeHooks()
+	if parent != nil {
+		for _, hook := range hooks {
+			parent.addHook(hook)
+		}
+		return nil
+	}
+
+	for _, hook := range hooks {
+		runAfterCommitHook(ctx, hook)
+	}
+	return nil
+}
+
+// AfterCommit 注册事务提交后执行的回调（如发送邮件、发布消息）
+// 事务回滚时回调不会执行；ctx中没有事务时立即执行
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	state := txStateFromContext(ctx)
+	if state == nil {
+		runAfterCommitHook(ctx, fn)
+		return
+	}
+	state.addHook(fn)
+}
+
+// InTransaction 判断ctx中是否存在事务
+func InTransaction(ctx context.Context) bool {
+	return txStateFromContext(ctx) != nil
+}
+
+// TxFromContext 获取ctx中的事务句柄
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	state := txStateFromContext(ctx)
+	if state == nil {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+// txStateFromContext 获取ctx中的事务状态
+func txStateFromContext(ctx context.Context) *txState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(txContextKey{}).(*txState)
+	return state
+}
+
+// runAfterCommitHook 执行提交后回调，回调panic不影响已提交的事务
+func runAfterCommitHook(ctx context.Context, fn func(ctx context.Context)) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Error("事务提交后回调执行异常", logger.Field("error", err))
+		}
+	}()
+	fn(ctx)
+}
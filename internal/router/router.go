@@ -2,9 +2,23 @@
 package router
 
 import (
+	"context"
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"ruleback/internal/config"
 	"ruleback/internal/middleware"
 	"ruleback/internal/wire"
+	"ruleback/pkg/diagnostics"
+	"ruleback/pkg/logger"
+	"ruleback/pkg/metrics"
+	"ruleback/pkg/module"
+)
+
+const (
+	// healthCheckTimeout 健康检查超时时间
+	healthCheckTimeout = 3 * time.Second
 )
 
 // RouteRegister 路由注册函数类型
@@ -12,29 +26,90 @@ type RouteRegister func(rg *gin.RouterGroup, handlers *wire.Handlers)
 
 // Setup 初始化并配置路由
 // handlers: Wire注入的Handler实例
+// modules: 已注册的模块，提供业务路由和健康检查（可为nil）
 // customRoutes: 自定义路由注册函数（可选）
-func Setup(handlers *wire.Handlers, customRoutes ...RouteRegister) *gin.Engine {
+func Setup(handlers *wire.Handlers, modules *module.Registry, customRoutes ...RouteRegister) *gin.Engine {
 	r := gin.New()
 
 	registerGlobalMiddleware(r)
-	registerHealthRoutes(r)
-	registerAPIRoutes(r, handlers, customRoutes...)
+	if !adminEnabled() {
+		registerOpsRoutes(r, modules)
+	}
+	registerAPIRoutes(r, handlers, modules, customRoutes...)
 
 	return r
 }
 
+// SetupAdmin 初始化运维服务器路由，配置了 server.admin_address 时使用
+// 运维服务器只监听内网地址，不注册访问日志，避免探针请求刷屏
+func SetupAdmin(modules *module.Registry) *gin.Engine {
+	r := gin.New()
+
+	r.Use(middleware.Recovery())
+	r.Use(middleware.RequestID())
+	registerOpsRoutes(r, modules)
+
+	return r
+}
+
+// adminEnabled 是否单独启动运维服务器
+func adminEnabled() bool {
+	cfg := config.Get()
+	return cfg != nil && cfg.Server.IsAdminEnabled()
+}
+
+// registerOpsRoutes 注册运维路由
+// 启用运维服务器时注册到运维服务器，否则注册到主服务器
+func registerOpsRoutes(r *gin.Engine, modules *module.Registry) {
+	registerHealthRoutes(r, modules)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	registerDiagnosticsRoutes(r)
+	// 在此添加运维端点
+}
+
+// registerDiagnosticsRoutes 注册 /debug 下的pprof等诊断端点
+// 开发环境以外默认不注册，需显式配置 diagnostics.enabled: true；
+// 配置了 diagnostics.token 时校验 X-Diagnostics-Token，未配置时只注册在运维服务器上，依靠运维端口的网络隔离
+func registerDiagnosticsRoutes(r *gin.Engine) {
+	cfg := config.Get()
+	if cfg == nil || !cfg.IsDiagnosticsEnabled() {
+		return
+	}
+	group := r.Group(diagnostics.Prefix)
+	switch {
+	case cfg.Diagnostics.Token != "":
+		group.Use(middleware.DiagnosticsToken(cfg.Diagnostics.Token))
+	case !cfg.Server.IsAdminEnabled():
+		logger.Warn("未配置 diagnostics.token 且未启用运维服务器，不注册诊断端点")
+		return
+	}
+	diagnostics.Register(group)
+}
+
 // registerGlobalMiddleware 注册全局中间件
 func registerGlobalMiddleware(r *gin.Engine) {
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Tracing())
 	r.Use(middleware.Logger())
+	r.Use(middleware.BodyLogger())
 	r.Use(middleware.Recovery())
 	r.Use(middleware.CORS())
 	r.Use(middleware.RequestID())
 }
 
 // registerHealthRoutes 注册健康检查路由
-func registerHealthRoutes(r *gin.Engine) {
+// /health 执行所有模块的健康检查，任一检查失败时返回503
+func registerHealthRoutes(r *gin.Engine, modules *module.Registry) {
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "healthy"})
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+		defer cancel()
+
+		checks, healthy := modules.CheckHealth(ctx)
+		status, code := "healthy", http.StatusOK
+		if !healthy {
+			status, code = "unhealthy", http.StatusServiceUnavailable
+		}
+		c.JSON(code, gin.H{"status": status, "checks": checks}) //archlint:ignore 探针约定的响应格式，不使用统一响应包装
 	})
 
 	r.GET("/ping", func(c *gin.Context) {
@@ -43,11 +118,15 @@ func registerHealthRoutes(r *gin.Engine) {
 }
 
 // registerAPIRoutes 注册API路由
-func registerAPIRoutes(r *gin.Engine, handlers *wire.Handlers, customRoutes ...RouteRegister) {
+func registerAPIRoutes(r *gin.Engine, handlers *wire.Handlers, modules *module.Registry, customRoutes ...RouteRegister) {
 	v1 := r.Group("/api/v1")
+	v1.Use(middleware.RateLimit(100, 60), middleware.ETag())
 	{
-		// 注册公开路由（无需认证）
-		registerPublicRoutes(v1, handlers)
+		// 注册模块路由
+		modules.RegisterRoutes(module.Routes{
+			Public:        v1,
+			Authenticated: authenticatedGroup(v1),
+		})
 
 		// 注册自定义路由
 		for _, register := range customRoutes {
@@ -56,30 +135,28 @@ func registerAPIRoutes(r *gin.Engine, handlers *wire.Handlers, customRoutes ...R
 	}
 }
 
-// registerPublicRoutes 注册公开路由（无需认证）
-// 使用框架时，可以在此处添加不需要认证的路由
-// 示例:
-//
-//	auth := rg.Group("/auth")
-//	{
-//	    auth.POST("/login", handlers.AuthHandler.Login)
-//	    auth.POST("/register", handlers.AuthHandler.Register)
-//	}
-func registerPublicRoutes(rg *gin.RouterGroup, handlers *wire.Handlers) {
-	// 在此添加公开路由
+// authenticatedGroup 创建带认证中间件的路由分组，未指定认证中间件时使用 middleware.Auth
+func authenticatedGroup(rg *gin.RouterGroup, auth ...gin.HandlerFunc) *gin.RouterGroup {
+	if len(auth) == 0 {
+		auth = []gin.HandlerFunc{middleware.Auth()}
+	}
+	authenticated := rg.Group("")
+	authenticated.Use(auth...)
+	return authenticated
 }
 
 // RegisterAuthenticatedRoutes 返回一个带认证中间件的路由注册函数
+// auth 为空时使用JWT认证（middleware.Auth），内部服务可传入 middleware.ClientCert() 使用客户端证书认证
 // 使用示例:
 //
-//	router.Setup(handlers, router.RegisterAuthenticatedRoutes(func(rg *gin.RouterGroup, h *wire.Handlers) {
+//	router.Setup(handlers, modules, router.RegisterAuthenticatedRoutes(func(rg *gin.RouterGroup, h *wire.Handlers) {
 //	    users := rg.Group("/users")
 //	    users.GET("", h.UserHandler.List)
 //	}))
-func RegisterAuthenticatedRoutes(register RouteRegister) RouteRegister {
+//
+//	router.Setup(handlers, modules, router.RegisterAuthenticatedRoutes(registerInternalRoutes, middleware.ClientCert()))
+func RegisterAuthenticatedRoutes(register RouteRegister, auth ...gin.HandlerFunc) RouteRegister {
 	return func(rg *gin.RouterGroup, handlers *wire.Handlers) {
-		authenticated := rg.Group("")
-		authenticated.Use(middleware.Auth())
-		register(authenticated, handlers)
+		register(authenticatedGroup(rg, auth...), handlers)
 	}
 }
@@ -0,0 +1,38 @@
+// Package service 业务逻辑层
+package service
+
+import (
+	"context"
+
+	"ruleback/internal/model"
+	"ruleback/internal/repository"
+	apperrors "ruleback/pkg/errors"
+	"ruleback/pkg/logger"
+)
+
+// AuditLogService 变更历史业务逻辑层
+type AuditLogService struct {
+	repo *repository.AuditLogRepository
+}
+
+// NewAuditLogService 创建AuditLogService实例（用于Wire依赖注入）
+func NewAuditLogService(repo *repository.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{repo: repo}
+}
+
+// History 获取指定记录的变更历史，只允许查询记录变更历史的表
+func (s *AuditLogService) History(ctx context.Context, query *model.AuditLogQuery) ([]*model.AuditLog, int64, error) {
+	if !s.repo.IsAuditedTable(query.Table) {
+		return nil, 0, apperrors.New(apperrors.CodeInvalidParams, "该表未记录变更历史")
+	}
+	query.PageQuery.SetDefaults()
+
+	logs, total, err := s.repo.ListByRecord(ctx, query)
+	if err != nil {
+		logger.Error("获取变更历史失败", logger.Err(err),
+			logger.String("table", query.Table), logger.String("record_id", query.RecordID))
+		return nil, 0, apperrors.Wrap(apperrors.CodeDatabaseError, "获取变更历史失败", err)
+	}
+
+	return logs, total, nil
+}
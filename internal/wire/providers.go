@@ -3,7 +3,9 @@ package wire
 
 import (
 	"gorm.io/gorm"
+	"ruleback/internal/handler"
 	"ruleback/internal/repository"
+	"ruleback/internal/service"
 )
 
 // ProvideBaseRepository 提供BaseRepository实例
@@ -11,6 +13,26 @@ func ProvideBaseRepository(db *gorm.DB) *repository.BaseRepository {
 	return repository.NewBaseRepository(db)
 }
 
+// ProvideTxManager 提供TxManager实例
+func ProvideTxManager(db *gorm.DB) *repository.TxManager {
+	return repository.NewTxManager(db)
+}
+
+// ProvideAuditLogRepository 提供AuditLogRepository实例
+func ProvideAuditLogRepository(base *repository.BaseRepository) *repository.AuditLogRepository {
+	return repository.NewAuditLogRepository(base)
+}
+
+// ProvideAuditLogService 提供AuditLogService实例
+func ProvideAuditLogService(repo *repository.AuditLogRepository) *service.AuditLogService {
+	return service.NewAuditLogService(repo)
+}
+
+// ProvideAuditLogHandler 提供AuditLogHandler实例
+func ProvideAuditLogHandler(svc *service.AuditLogService) *handler.AuditLogHandler {
+	return handler.NewAuditLogHandler(svc)
+}
+
 // Handlers 包含所有Handler实例
 // 使用框架时，请在此结构体中添加你的Handler
 // 示例:
@@ -20,6 +42,7 @@ func ProvideBaseRepository(db *gorm.DB) *repository.BaseRepository {
 //	    ProductHandler *handler.ProductHandler
 //	}
 type Handlers struct {
+	AuditLogHandler *handler.AuditLogHandler
 	// 在此添加你的Handler字段
 }
 
@@ -30,6 +53,6 @@ type Handlers struct {
 //	func ProvideHandlers(userHandler *handler.UserHandler) *Handlers {
 //	    return &Handlers{UserHandler: userHandler}
 //	}
-func ProvideHandlers() *Handlers {
-	return &Handlers{}
+func ProvideHandlers(auditLogHandler *handler.AuditLogHandler) *Handlers {
+	return &Handlers{AuditLogHandler: auditLogHandler}
 }
@@ -15,6 +15,7 @@ import (
 //
 //	var ProviderSet = wire.NewSet(
 //	    ProvideBaseRepository,
+//	    ProvideTxManager,
 //	    ProvideUserRepository,
 //	    ProvideUserService,
 //	    ProvideUserHandler,
@@ -22,6 +23,10 @@ import (
 //	)
 var ProviderSet = wire.NewSet(
 	ProvideBaseRepository,
+	ProvideTxManager,
+	ProvideAuditLogRepository,
+	ProvideAuditLogService,
+	ProvideAuditLogHandler,
 	ProvideHandlers,
 )
 
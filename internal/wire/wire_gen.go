@@ -16,7 +16,11 @@ import (
 // InitializeHandlers 初始化所有Handler
 // 使用框架时，Wire会根据ProviderSet自动生成依赖注入代码
 func InitializeHandlers(db *gorm.DB) (*Handlers, error) {
-	handlers := ProvideHandlers()
+	baseRepository := ProvideBaseRepository(db)
+	auditLogRepository := ProvideAuditLogRepository(baseRepository)
+	auditLogService := ProvideAuditLogService(auditLogRepository)
+	auditLogHandler := ProvideAuditLogHandler(auditLogService)
+	handlers := ProvideHandlers(auditLogHandler)
 	return handlers, nil
 }
 
@@ -28,6 +32,7 @@ func InitializeHandlers(db *gorm.DB) (*Handlers, error) {
 //
 //	var ProviderSet = wire.NewSet(
 //	    ProvideBaseRepository,
+//	    ProvideTxManager,
 //	    ProvideUserRepository,
 //	    ProvideUserService,
 //	    ProvideUserHandler,
@@ -35,5 +40,9 @@ func InitializeHandlers(db *gorm.DB) (*Handlers, error) {
 //	)
 var ProviderSet = wire.NewSet(
 	ProvideBaseRepository,
+	ProvideTxManager,
+	ProvideAuditLogRepository,
+	ProvideAuditLogService,
+	ProvideAuditLogHandler,
 	ProvideHandlers,
 )
@@ -0,0 +1,65 @@
+// Package buildinfo 构建信息，通过 -ldflags 在编译时注入
+//
+//	go build -ldflags "-X ruleback/pkg/buildinfo.Version=v1.0.0 \
+//	    -X ruleback/pkg/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	    -X ruleback/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// 编译时注入的构建信息
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get 返回构建信息，未注入提交和构建时间时从Go模块的VCS信息中读取
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// String 返回单行可读的构建信息
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit: %s, built: %s, %s %s)",
+		i.Version, orUnknown(i.Commit), orUnknown(i.BuildTime), i.GoVersion, i.Platform)
+}
+
+// orUnknown 空值显示为 unknown
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
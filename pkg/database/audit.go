@@ -0,0 +1,83 @@
+package database
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"ruleback/pkg/reqctx"
+)
+
+// 审计字段名，与 model.AuditModel 保持一致
+const (
+	fieldCreatedBy = "CreatedBy"
+	fieldUpdatedBy = "UpdatedBy"
+	fieldDeletedBy = "DeletedBy"
+)
+
+// registerAuditCallbacks 注册审计字段回调，从请求上下文读取操作人填充审计字段
+// DeletedBy 由软删除字段类型 DeletedAt 在删除语句中写入
+func registerAuditCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").
+		Register("audit:fill_created_by", fillCreatedBy); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").
+		Register("audit:fill_updated_by", fillUpdatedBy)
+}
+
+// fillCreatedBy 创建时填充 CreatedBy 和 UpdatedBy（已手动赋值的字段不覆盖）
+func fillCreatedBy(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	actor, ok := reqctx.GetUserID(db.Statement.Context)
+	if !ok {
+		return
+	}
+
+	fields := make([]*schema.Field, 0, 2)
+	for _, name := range []string{fieldCreatedBy, fieldUpdatedBy} {
+		if field := db.Statement.Schema.LookUpField(name); field != nil {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	fill := func(rv reflect.Value) {
+		for _, field := range fields {
+			if _, isZero := field.ValueOf(ctx, rv); isZero {
+				_ = field.Set(ctx, rv, actor)
+			}
+		}
+	}
+
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			elem := reflect.Indirect(rv.Index(i))
+			if elem.Kind() == reflect.Struct {
+				fill(elem)
+			}
+		}
+	case reflect.Struct:
+		fill(rv)
+	}
+}
+
+// fillUpdatedBy 更新时填充 UpdatedBy
+func fillUpdatedBy(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	if db.Statement.Schema.LookUpField(fieldUpdatedBy) == nil {
+		return
+	}
+	if actor, ok := reqctx.GetUserID(db.Statement.Context); ok {
+		db.Statement.SetColumn(fieldUpdatedBy, actor, true)
+	}
+}
@@ -42,12 +42,32 @@ func Init(cfg *config.DatabaseConfig) error {
 			return
 		}
 
+		if err := registerAuditCallbacks(db); err != nil {
+			initErr = fmt.Errorf("注册审计回调失败: %w", err)
+			return
+		}
+
+		if err := registerMetricsCallbacks(db); err != nil {
+			initErr = fmt.Errorf("注册指标回调失败: %w", err)
+			return
+		}
+
+		if err := registerTracingCallbacks(db); err != nil {
+			initErr = fmt.Errorf("注册链路追踪回调失败: %w", err)
+			return
+		}
+
 		sqlDB, err := db.DB()
 		if err != nil {
 			initErr = fmt.Errorf("获取数据库连接失败: %w", err)
 			return
 		}
 
+		if err := registerDBStats(sqlDB, cfg.Database); err != nil {
+			initErr = fmt.Errorf("注册连接池指标失败: %w", err)
+			return
+		}
+
 		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 		sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
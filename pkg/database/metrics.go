@@ -0,0 +1,64 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+	"ruleback/pkg/metrics"
+)
+
+// metricsStartKey 操作开始时间在 gorm 实例中的键名
+const metricsStartKey = "metrics:start"
+
+// registerMetricsCallbacks 注册数据库操作耗时回调，按操作类型和表名记录
+// 计时从第一个回调开始、到最后一个回调结束，包含审计等其他回调的耗时
+func registerMetricsCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("metrics:start_create", startTimer),
+		cb.Create().After("*").Register("metrics:observe_create", observeDB("create")),
+		cb.Query().Before("*").Register("metrics:start_query", startTimer),
+		cb.Query().After("*").Register("metrics:observe_query", observeDB("query")),
+		cb.Update().Before("*").Register("metrics:start_update", startTimer),
+		cb.Update().After("*").Register("metrics:observe_update", observeDB("update")),
+		cb.Delete().Before("*").Register("metrics:start_delete", startTimer),
+		cb.Delete().After("*").Register("metrics:observe_delete", observeDB("delete")),
+		cb.Row().Before("*").Register("metrics:start_row", startTimer),
+		cb.Row().After("*").Register("metrics:observe_row", observeDB("row")),
+		cb.Raw().Before("*").Register("metrics:start_raw", startTimer),
+		cb.Raw().After("*").Register("metrics:observe_raw", observeDB("raw")),
+	)
+}
+
+// startTimer 记录操作开始时间
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+// observeDB 返回记录操作耗时的回调，原生SQL等无法确定表名时记为 unknown
+func observeDB(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		metrics.ObserveDB(operation, table, time.Since(start))
+	}
+}
+
+// registerDBStats 注册连接池指标（go_sql_*），dbName 作为 db_name 标签
+func registerDBStats(sqlDB *sql.DB, dbName string) error {
+	return metrics.Register(collectors.NewDBStatsCollector(sqlDB, dbName))
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 版本化数据库迁移
+type Migration struct {
+	ID   string                  // 唯一标识，按字典序执行，格式: 时间戳_描述，如 20261018120000_create_users
+	Up   func(tx *gorm.DB) error // 执行迁移
+	Down func(tx *gorm.DB) error // 回滚迁移，为nil时不可回滚
+}
+
+// MigrationRecord 已执行迁移的记录
+type MigrationRecord struct {
+	ID        string `gorm:"primaryKey;size:191"`
+	AppliedAt time.Time
+}
+
+// TableName 指定表名
+func (MigrationRecord) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	ID        string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// ModelMigration 创建基于 AutoMigrate 的迁移，回滚时删除模型对应的表
+func ModelMigration(id string, models ...interface{}) Migration {
+	return Migration{
+		ID: id,
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(models...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(models...)
+		},
+	}
+}
+
+// MigrateUp 按顺序执行所有未执行的迁移，返回本次执行的迁移ID
+// 每个迁移在独立事务中执行并记录到 schema_migrations，失败时停止
+func MigrateUp(db *gorm.DB, migrations []Migration) ([]string, error) {
+	sorted, applied, err := prepareMigrations(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for _, m := range sorted {
+		if _, ok := applied[m.ID]; ok {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&MigrationRecord{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("执行迁移 %s 失败: %w", m.ID, err)
+		}
+		done = append(done, m.ID)
+	}
+	return done, nil
+}
+
+// MigrateDown 按执行顺序倒序回滚最近 steps 个迁移，返回本次回滚的迁移ID
+func MigrateDown(db *gorm.DB, migrations []Migration, steps int) ([]string, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("回滚步数必须大于0")
+	}
+
+	sorted, applied, err := prepareMigrations(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []string
+	for i := len(sorted) - 1; i >= 0 && len(done) < steps; i-- {
+		m := sorted[i]
+		if _, ok := applied[m.ID]; !ok {
+			continue
+		}
+		if m.Down == nil {
+			return done, fmt.Errorf("迁移 %s 不支持回滚", m.ID)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&MigrationRecord{ID: m.ID}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("回滚迁移 %s 失败: %w", m.ID, err)
+		}
+		done = append(done, m.ID)
+	}
+	return done, nil
+}
+
+// MigrationStatuses 返回所有迁移的执行状态，按ID排序
+func MigrationStatuses(db *gorm.DB, migrations []Migration) ([]MigrationStatus, error) {
+	sorted, applied, err := prepareMigrations(db, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(sorted))
+	for _, m := range sorted {
+		record, ok := applied[m.ID]
+		statuses = append(statuses, MigrationStatus{ID: m.ID, Applied: ok, AppliedAt: record.AppliedAt})
+	}
+	return statuses, nil
+}
+
+// prepareMigrations 校验并排序迁移，确保记录表存在并读取已执行的迁移
+func prepareMigrations(db *gorm.DB, migrations []Migration) ([]Migration, map[string]MigrationRecord, error) {
+	if db == nil {
+		return nil, nil, fmt.Errorf("数据库未初始化")
+	}
+
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	known := make(map[string]struct{}, len(sorted))
+	for _, m := range sorted {
+		if m.ID == "" || m.Up == nil {
+			return nil, nil, fmt.Errorf("迁移 %q 缺少ID或Up函数", m.ID)
+		}
+		if _, ok := known[m.ID]; ok {
+			return nil, nil, fmt.Errorf("迁移ID重复: %s", m.ID)
+		}
+		known[m.ID] = struct{}{}
+	}
+
+	if err := db.AutoMigrate(&MigrationRecord{}); err != nil {
+		return nil, nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+
+	var records []MigrationRecord
+	if err := db.Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	applied := make(map[string]MigrationRecord, len(records))
+	for _, r := range records {
+		if _, ok := known[r.ID]; !ok {
+			return nil, nil, fmt.Errorf("数据库中存在未知迁移 %s，请确认代码版本", r.ID)
+		}
+		applied[r.ID] = r
+	}
+	return sorted, applied, nil
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"database/sql/driver"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"ruleback/pkg/reqctx"
+)
+
+// DeletedAtType 软删除字段的类型，用于识别模型中的软删除字段
+var DeletedAtType = reflect.TypeOf(DeletedAt{})
+
+// DeletedAt 软删除字段类型，查询和更新行为与 gorm.DeletedAt 相同
+// 软删除时在同一条UPDATE语句中写入 deleted_at，模型有 DeletedBy 字段且请求上下文中有用户ID时同时写入 deleted_by
+type DeletedAt gorm.DeletedAt
+
+// Scan 实现 sql.Scanner
+func (n *DeletedAt) Scan(value interface{}) error {
+	return (*gorm.DeletedAt)(n).Scan(value)
+}
+
+// Value 实现 driver.Valuer
+func (n DeletedAt) Value() (driver.Value, error) {
+	return gorm.DeletedAt(n).Value()
+}
+
+// MarshalJSON 未删除时输出null
+func (n DeletedAt) MarshalJSON() ([]byte, error) {
+	return gorm.DeletedAt(n).MarshalJSON()
+}
+
+// UnmarshalJSON 解析null或时间
+func (n *DeletedAt) UnmarshalJSON(b []byte) error {
+	return (*gorm.DeletedAt)(n).UnmarshalJSON(b)
+}
+
+// QueryClauses 查询时排除已删除的记录
+func (DeletedAt) QueryClauses(f *schema.Field) []clause.Interface {
+	return gorm.DeletedAt{}.QueryClauses(f)
+}
+
+// UpdateClauses 更新时排除已删除的记录
+func (DeletedAt) UpdateClauses(f *schema.Field) []clause.Interface {
+	return gorm.DeletedAt{}.UpdateClauses(f)
+}
+
+// DeleteClauses 删除时改为更新 deleted_at 和 deleted_by
+func (DeletedAt) DeleteClauses(f *schema.Field) []clause.Interface {
+	return []clause.Interface{softDeleteClause{Field: f}}
+}
+
+// softDeleteClause 软删除子句，在 gorm.SoftDeleteDeleteClause 的基础上写入 deleted_by
+type softDeleteClause struct {
+	Field *schema.Field
+}
+
+// Name 不作为独立子句输出
+func (softDeleteClause) Name() string {
+	return ""
+}
+
+// Build 不输出SQL，语句由 ModifyStatement 构建
+func (softDeleteClause) Build(clause.Builder) {}
+
+// MergeClause 无需合并
+func (softDeleteClause) MergeClause(*clause.Clause) {}
+
+// ModifyStatement 把DELETE改写为UPDATE，Unscoped 或已有SQL时保持硬删除
+func (sd softDeleteClause) ModifyStatement(stmt *gorm.Statement) {
+	if stmt.SQL.Len() > 0 || stmt.Unscoped {
+		return
+	}
+
+	now := stmt.DB.NowFunc()
+	set := clause.Set{{Column: clause.Column{Name: sd.Field.DBName}, Value: now}}
+	stmt.SetColumn(sd.Field.DBName, now, true)
+	if deletedBy := stmt.Schema.LookUpField(fieldDeletedBy); deletedBy != nil {
+		if actor, ok := reqctx.GetUserID(stmt.Context); ok {
+			set = append(set, clause.Assignment{Column: clause.Column{Name: deletedBy.DBName}, Value: actor})
+			stmt.SetColumn(deletedBy.DBName, actor, true)
+		}
+	}
+	stmt.AddClause(set)
+
+	// 按传入模型的主键限定删除范围
+	_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+	column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+	if len(values) > 0 {
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+	}
+	if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+		_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+		column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+	}
+
+	// 已删除的记录不再更新
+	for _, c := range (DeletedAt{}).QueryClauses(sd.Field) {
+		if modifier, ok := c.(gorm.StatementModifier); ok {
+			modifier.ModifyStatement(stmt)
+		}
+	}
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.Build(stmt.DB.Callback().Update().Clauses...)
+}
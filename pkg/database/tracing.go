@@ -0,0 +1,84 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"ruleback/pkg/tracing"
+)
+
+// tracingSpanKey 当前操作的span在 gorm 实例中的键名
+const tracingSpanKey = "tracing:span"
+
+// registerTracingCallbacks 注册数据库操作的链路追踪回调，作为请求span的子span
+// 只有通过 WithContext 传入请求context的操作才能关联到当前请求
+func registerTracingCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("tracing:start_create", startSpan("create")),
+		cb.Create().After("*").Register("tracing:end_create", endSpan),
+		cb.Query().Before("*").Register("tracing:start_query", startSpan("query")),
+		cb.Query().After("*").Register("tracing:end_query", endSpan),
+		cb.Update().Before("*").Register("tracing:start_update", startSpan("update")),
+		cb.Update().After("*").Register("tracing:end_update", endSpan),
+		cb.Delete().Before("*").Register("tracing:start_delete", startSpan("delete")),
+		cb.Delete().After("*").Register("tracing:end_delete", endSpan),
+		cb.Row().Before("*").Register("tracing:start_row", startSpan("row")),
+		cb.Row().After("*").Register("tracing:end_row", endSpan),
+		cb.Raw().Before("*").Register("tracing:start_raw", startSpan("raw")),
+		cb.Raw().After("*").Register("tracing:end_raw", endSpan),
+	)
+}
+
+// startSpan 返回创建client span的回调，span名称为 "操作 表名"
+func startSpan(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// 没有上级span（如启动迁移、后台任务未传入context）时不创建孤立的span
+			return
+		}
+
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(db.Dialector.Name()),
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+// endSpan 记录SQL、影响行数和错误后结束span，记录不存在不视为错误
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,160 @@
+// Package diagnostics 运行时诊断端点：pprof、goroutine 堆栈、GC 统计和构建信息
+// 端点由 router 注册到校验诊断令牌的 /debug 分组，用于排查线上延迟和内存问题
+package diagnostics
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/pkg/buildinfo"
+	"ruleback/pkg/response"
+)
+
+// Prefix 诊断端点的路由前缀，net/http/pprof 按此前缀解析profile名称
+const Prefix = "/debug"
+
+// recentPauses GC统计中返回的最近暂停次数
+const recentPauses = 10
+
+// GCStats GC和内存统计
+type GCStats struct {
+	NumGC         uint32    `json:"num_gc"`
+	LastGC        time.Time `json:"last_gc"`
+	PauseTotalMs  float64   `json:"pause_total_ms"`
+	RecentPauses  []float64 `json:"recent_pauses_ms"` // 最近的GC暂停耗时，最新的在前
+	GCCPUFraction float64   `json:"gc_cpu_fraction"`  // 启动以来GC占用的CPU比例
+	NextGC        uint64    `json:"next_gc_bytes"`    // 下次GC触发的堆大小
+	HeapAlloc     uint64    `json:"heap_alloc_bytes"`
+	HeapInuse     uint64    `json:"heap_inuse_bytes"`
+	HeapObjects   uint64    `json:"heap_objects"`
+	Sys           uint64    `json:"sys_bytes"`
+	Goroutines    int       `json:"goroutines"`
+	GOMAXPROCS    int       `json:"gomaxprocs"`
+	GOGC          int       `json:"gogc"`
+	MemoryLimit   int64     `json:"memory_limit_bytes"`
+}
+
+// BuildInfo 构建信息和依赖模块版本
+type BuildInfo struct {
+	buildinfo.Info
+	Deps []Module `json:"deps"`
+}
+
+// Module 依赖模块
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// Register 在路由分组上注册诊断端点，分组路径必须为 Prefix
+// 分组应带令牌校验中间件或只注册在运维服务器上，pprof 会暴露内存中的数据
+func Register(rg *gin.RouterGroup) {
+	rg.GET("/pprof/*name", servePprof)
+	rg.POST("/pprof/*name", servePprof)
+	rg.GET("/goroutines", Goroutines)
+	rg.GET("/gc", GC)
+	rg.GET("/buildinfo", Build)
+}
+
+// servePprof 分发 pprof 请求
+// profile、trace 的 seconds 参数不能超过 server.write_timeout，否则 pprof 直接返回错误
+func servePprof(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("name"), "/") {
+	case "cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		// 索引页和 heap、goroutine、allocs 等命名profile
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// Goroutines 以文本形式输出所有goroutine的完整堆栈
+func Goroutines(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := rpprof.Lookup("goroutine").WriteTo(c.Writer, 2); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// GC 返回GC和内存统计
+func GC(c *gin.Context) {
+	response.SuccessWithData(c, ReadGCStats())
+}
+
+// Build 返回构建信息和依赖模块版本
+func Build(c *gin.Context) {
+	response.SuccessWithData(c, ReadBuildInfo())
+}
+
+// ReadGCStats 读取GC和内存统计，ReadMemStats 会短暂暂停所有goroutine，不要高频调用
+func ReadGCStats() GCStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+	gc.Pause = gc.Pause[:min(len(gc.Pause), recentPauses)]
+
+	pauses := make([]float64, 0, len(gc.Pause))
+	for _, p := range gc.Pause {
+		pauses = append(pauses, milliseconds(p))
+	}
+
+	settings := []metrics.Sample{{Name: "/gc/gogc:percent"}, {Name: "/gc/gomemlimit:bytes"}}
+	metrics.Read(settings)
+
+	return GCStats{
+		NumGC:         mem.NumGC,
+		LastGC:        gc.LastGC,
+		PauseTotalMs:  milliseconds(gc.PauseTotal),
+		RecentPauses:  pauses,
+		GCCPUFraction: mem.GCCPUFraction,
+		NextGC:        mem.NextGC,
+		HeapAlloc:     mem.HeapAlloc,
+		HeapInuse:     mem.HeapInuse,
+		HeapObjects:   mem.HeapObjects,
+		Sys:           mem.Sys,
+		Goroutines:    runtime.NumGoroutine(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		GOGC:          int(settings[0].Value.Uint64()),
+		MemoryLimit:   int64(settings[1].Value.Uint64()),
+	}
+}
+
+// ReadBuildInfo 读取构建信息和依赖模块版本
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{Info: buildinfo.Get()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, dep := range bi.Deps {
+		m := Module{Path: dep.Path, Version: dep.Version}
+		if dep.Replace != nil {
+			m.Replace = dep.Replace.Path + " " + dep.Replace.Version
+		}
+		info.Deps = append(info.Deps, m)
+	}
+	return info
+}
+
+// milliseconds 转换为毫秒，保留小数
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
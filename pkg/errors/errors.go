@@ -54,6 +54,20 @@ var codeMessages = map[int]string{
 	CodeTokenInvalid:      "Token无效",
 }
 
+// retryableCodes 服务端临时故障的错误码，同一个请求重试可能成功
+var retryableCodes = map[int]bool{
+	CodeUnknown:       true,
+	CodeInternalError: true,
+	CodeDatabaseError: true,
+	CodeRateLimited:   true,
+	CodeTimeout:       true,
+}
+
+// IsRetryable 判断错误码是否表示服务端临时故障，middleware.Idempotency 据此决定是否允许用同一个键重试
+func IsRetryable(code int) bool {
+	return retryableCodes[code]
+}
+
 // GetMessage 获取错误码对应的默认消息
 func GetMessage(code int) string {
 	if msg, ok := codeMessages[code]; ok {
@@ -139,6 +153,30 @@ func GetCode(err error) int {
 	return CodeInternalError
 }
 
+// VersionConflictError 乐观锁版本冲突错误，携带记录的当前版本
+type VersionConflictError struct {
+	CurrentVersion uint
+}
+
+// Error 实现 error 接口
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("版本冲突，当前版本为 %d", e.CurrentVersion)
+}
+
+// NewVersionConflict 创建版本冲突的应用错误（错误码为 CodeConflict）
+func NewVersionConflict(currentVersion uint) *AppError {
+	return Wrap(CodeConflict, "数据已被修改，请刷新后重试", &VersionConflictError{CurrentVersion: currentVersion})
+}
+
+// GetCurrentVersion 从错误链中获取版本冲突时记录的当前版本
+func GetCurrentVersion(err error) (uint, bool) {
+	var conflictErr *VersionConflictError
+	if errors.As(err, &conflictErr) {
+		return conflictErr.CurrentVersion, true
+	}
+	return 0, false
+}
+
 // 预定义错误实例
 var (
 	ErrInvalidParams     = NewWithCode(CodeInvalidParams)
@@ -0,0 +1,65 @@
+// Package httpcache 服务端HTTP响应缓存
+// 缓存由 middleware.Cache 写入，Service 在写操作成功后按标签调用 Invalidate 删除
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// defaultMaxEntries 未设置存储时进程内缓存的最大条数
+const defaultMaxEntries = 10000
+
+// Entry 缓存的响应
+type Entry struct {
+	Status    int
+	Header    http.Header
+	Body      []byte
+	Tags      []string  // 失效标签，如 products、product:42
+	StoredAt  time.Time // 开始处理请求的时间，之后任一标签失效过时 Set 不保存
+	ExpiresAt time.Time
+}
+
+// Store 响应缓存存储
+type Store interface {
+	// Get 获取未过期的缓存，不存在时返回nil
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set 保存缓存，entry 保存后不再修改；entry.StoredAt 之后任一标签被 Invalidate 过时丢弃，避免写入失效前读到的旧数据
+	Set(ctx context.Context, key string, entry *Entry) error
+	// Invalidate 删除带有任一标签的缓存，并记录标签的失效时间
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+var (
+	defaultStore atomic.Pointer[storeHolder]
+	fallback     = NewMemoryStore(defaultMaxEntries)
+)
+
+// storeHolder 包装接口值以便原子替换
+type storeHolder struct {
+	store Store
+}
+
+// SetStore 设置全局存储，由 http_cache 模块在启动时根据配置调用
+func SetStore(s Store) {
+	defaultStore.Store(&storeHolder{store: s})
+}
+
+// GetStore 获取全局存储，未设置时使用进程内存储
+func GetStore() Store {
+	if h := defaultStore.Load(); h != nil && h.store != nil {
+		return h.store
+	}
+	return fallback
+}
+
+// Invalidate 删除带有任一标签的缓存响应，Service 在写操作成功后调用
+// 在事务中修改数据时放在 repository.AfterCommit 回调中，避免回滚后缓存已被删除、提交前又被旧数据填充
+func Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return GetStore().Invalidate(ctx, tags...)
+}
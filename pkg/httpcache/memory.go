@@ -0,0 +1,125 @@
+package httpcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// maxInvalidations 保留的标签失效记录上限，超过时清空并以当前时间作为统一的失效时间
+const maxInvalidations = 10000
+
+// MemoryStore 进程内LRU缓存，超过最大条数时淘汰最久未使用的响应
+// 多实例部署时各实例不共享，Invalidate 只删除本实例的缓存
+type MemoryStore struct {
+	mu          sync.Mutex
+	maxEntries  int
+	lru         *list.List // 前端为最近使用
+	items       map[string]*list.Element
+	tags        map[string]map[string]struct{}
+	invalidated map[string]time.Time // 标签最近一次失效的时间
+	floor       time.Time            // 清空失效记录的时间，早于它开始的请求不再保存
+}
+
+// memoryItem LRU链表元素
+type memoryItem struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemoryStore 创建MemoryStore实例，maxEntries 为最大条数
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries:  maxEntries,
+		lru:         list.New(),
+		items:       make(map[string]*list.Element),
+		tags:        make(map[string]map[string]struct{}),
+		invalidated: make(map[string]time.Time),
+	}
+}
+
+// Get 获取未过期的缓存
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+	item := el.Value.(*memoryItem)
+	if !item.entry.ExpiresAt.After(time.Now()) {
+		s.remove(el)
+		return nil, nil
+	}
+	s.lru.MoveToFront(el)
+	return item.entry, nil
+}
+
+// Set 保存缓存，请求开始后任一标签失效过时丢弃
+func (s *MemoryStore) Set(ctx context.Context, key string, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(entry.Tags) > 0 && !entry.StoredAt.After(s.floor) {
+		return nil
+	}
+	for _, tag := range entry.Tags {
+		if at, ok := s.invalidated[tag]; ok && !entry.StoredAt.After(at) {
+			return nil
+		}
+	}
+
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+	s.items[key] = s.lru.PushFront(&memoryItem{key: key, entry: entry})
+	for _, tag := range entry.Tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	for s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back())
+	}
+	return nil
+}
+
+// Invalidate 删除带有任一标签的缓存，并记录标签的失效时间
+func (s *MemoryStore) Invalidate(ctx context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if len(s.invalidated)+len(tags) > maxInvalidations {
+		s.invalidated = make(map[string]time.Time)
+		s.floor = now
+	}
+	for _, tag := range tags {
+		s.invalidated[tag] = now
+		for key := range s.tags[tag] {
+			if el, ok := s.items[key]; ok {
+				s.remove(el)
+			}
+		}
+	}
+	return nil
+}
+
+// remove 删除链表元素及其标签索引
+func (s *MemoryStore) remove(el *list.Element) {
+	item := s.lru.Remove(el).(*memoryItem)
+	delete(s.items, item.key)
+	for _, tag := range item.entry.Tags {
+		if keys, ok := s.tags[tag]; ok {
+			delete(keys, item.key)
+			if len(keys) == 0 {
+				delete(s.tags, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package httpcache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestMemoryStore_Invalidate 失效删除已有缓存，失效前开始处理的请求不再写入缓存
+func TestMemoryStore_Invalidate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		tags       []string
+		setFirst   bool     // 失效前已保存
+		invalidate []string // 请求开始后、保存前失效的标签
+		flood      bool     // 失效记录超过上限
+		wantCached bool
+	}{
+		{name: "无失效时保存", tags: []string{"products", "product:1"}, wantCached: true},
+		{name: "失效删除已有缓存", tags: []string{"products", "product:1"}, setFirst: true, invalidate: []string{"product:1"}},
+		{name: "请求开始后标签失效时不保存", tags: []string{"products", "product:1"}, invalidate: []string{"products"}},
+		{name: "其他标签失效不影响保存", tags: []string{"products", "product:1"}, invalidate: []string{"product:2"}, wantCached: true},
+		{name: "不带标签的响应不受影响", invalidate: []string{"products"}, flood: true, wantCached: true},
+		{name: "失效记录清空后仍不保存", tags: []string{"products"}, invalidate: []string{"products"}, flood: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore(100)
+			start := time.Now()
+			entry := &Entry{Status: 200, Body: []byte("{}"), Tags: tt.tags, StoredAt: start, ExpiresAt: start.Add(time.Minute)}
+
+			if tt.setFirst {
+				if err := s.Set(ctx, "k", entry); err != nil {
+					t.Fatalf("保存失败: %v", err)
+				}
+			}
+			if err := s.Invalidate(ctx, tt.invalidate...); err != nil {
+				t.Fatalf("失效失败: %v", err)
+			}
+			if tt.flood {
+				for i := 0; i <= maxInvalidations; i++ {
+					_ = s.Invalidate(ctx, fmt.Sprintf("order:%d", i))
+				}
+			}
+			if !tt.setFirst {
+				if err := s.Set(ctx, "k", entry); err != nil {
+					t.Fatalf("保存失败: %v", err)
+				}
+			}
+
+			got, err := s.Get(ctx, "k")
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if (got != nil) != tt.wantCached {
+				t.Errorf("已缓存 = %v, 期望 %v", got != nil, tt.wantCached)
+			}
+		})
+	}
+}
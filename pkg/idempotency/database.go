@@ -0,0 +1,130 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KeyRecord 幂等键记录表，由 idempotency 模块的迁移创建
+type KeyRecord struct {
+	Key         string    `gorm:"column:idempotency_key;primaryKey;size:191"`
+	Fingerprint string    `gorm:"size:64;not null"`
+	Completed   bool      `gorm:"not null;default:false"`
+	Status      int       `gorm:"not null;default:0"`
+	Header      string    `gorm:"type:text"` // JSON格式的响应头
+	Body        []byte    // 响应体
+	LeaseToken  string    `gorm:"size:32;not null"` // 占用令牌
+	LockedUntil time.Time `gorm:"not null"`         // 处理中记录的占用截止时间
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+}
+
+// TableName 指定表名
+func (KeyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// DatabaseStore 基于数据库表的存储，多实例共享，依赖主键唯一约束保证只有一个请求占用成功
+type DatabaseStore struct {
+	db *gorm.DB
+}
+
+// NewDatabaseStore 创建DatabaseStore实例
+func NewDatabaseStore(db *gorm.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+// Begin 占用幂等键，先删除该键已过期或占用超时的记录再插入
+func (s *DatabaseStore) Begin(ctx context.Context, key, fingerprint string, lock, ttl time.Duration) (*Record, string, error) {
+	lease, err := newLeaseToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	stale := db.Where("expires_at <= ?", now).Or("completed = ? AND locked_until <= ?", false, now)
+	if err := db.Where("idempotency_key = ?", key).Where(stale).Delete(&KeyRecord{}).Error; err != nil {
+		return nil, "", err
+	}
+
+	row := KeyRecord{Key: key, Fingerprint: fingerprint, LeaseToken: lease, LockedUntil: now.Add(lock), ExpiresAt: now.Add(ttl), CreatedAt: now}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, lease, nil
+	}
+
+	var existing KeyRecord
+	if err := db.Where("idempotency_key = ?", key).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 插入冲突后记录又被释放，按处理中返回，由客户端稍后重试
+			return &Record{Key: key, Fingerprint: fingerprint, LockedUntil: now.Add(lock), ExpiresAt: now.Add(ttl)}, "", nil
+		}
+		return nil, "", err
+	}
+	record, err := existing.toRecord()
+	return record, "", err
+}
+
+// Complete 保存响应
+func (s *DatabaseStore) Complete(ctx context.Context, key, lease string, resp *Response) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return err
+	}
+	result := s.db.WithContext(ctx).Model(&KeyRecord{}).
+		Where("idempotency_key = ? AND lease_token = ?", key, lease).
+		Updates(map[string]interface{}{
+			"completed": true,
+			"status":    resp.Status,
+			"header":    string(header),
+			"body":      resp.Body,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release 删除本次占用的处理中记录
+func (s *DatabaseStore) Release(ctx context.Context, key, lease string) error {
+	return s.db.WithContext(ctx).
+		Where("idempotency_key = ? AND lease_token = ? AND completed = ?", key, lease, false).
+		Delete(&KeyRecord{}).Error
+}
+
+// Purge 删除过期记录
+func (s *DatabaseStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", before).Delete(&KeyRecord{})
+	return result.RowsAffected, result.Error
+}
+
+// toRecord 转换为 Record
+func (r *KeyRecord) toRecord() (*Record, error) {
+	record := &Record{Key: r.Key, Fingerprint: r.Fingerprint, LeaseToken: r.LeaseToken, LockedUntil: r.LockedUntil, ExpiresAt: r.ExpiresAt}
+	if !r.Completed {
+		return record, nil
+	}
+
+	var header http.Header
+	if r.Header != "" {
+		if err := json.Unmarshal([]byte(r.Header), &header); err != nil {
+			return nil, err
+		}
+	}
+	record.Response = &Response{Status: r.Status, Header: header, Body: r.Body}
+	return record, nil
+}
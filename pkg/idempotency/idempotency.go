@@ -0,0 +1,83 @@
+// Package idempotency 幂等键记录存储
+// 保存带 Idempotency-Key 的请求的首次响应，重试时原样返回，避免重复创建
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLeaseLost 占用已超时并被其他请求接管，本次请求的响应不再保存
+var ErrLeaseLost = errors.New("幂等键占用已失效")
+
+// Response 保存的响应
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record 幂等键记录
+type Record struct {
+	Key         string
+	Fingerprint string    // 请求方法、路径和请求体的摘要，用于识别同一个键被用于不同的请求
+	Response    *Response // 处理中时为nil
+	LeaseToken  string    // 占用成功时生成的随机令牌，Complete 和 Release 据此确认仍持有占用
+	LockedUntil time.Time // 处理中记录的占用截止时间，超过后视为处理该请求的实例已退出
+	ExpiresAt   time.Time
+}
+
+// Completed 请求是否已处理完成
+func (r *Record) Completed() bool {
+	return r.Response != nil
+}
+
+// Store 幂等键存储，实现必须保证 Begin 在并发下只有一个调用者占用成功
+type Store interface {
+	// Begin 占用幂等键：键不存在、已过期或处理中的记录超过占用时间时写入处理中的记录，返回 nil 和占用令牌；否则返回已有记录
+	// lock 为处理中记录的占用时间，ttl 为记录的保留时间
+	Begin(ctx context.Context, key, fingerprint string, lock, ttl time.Duration) (*Record, string, error)
+	// Complete 保存响应，之后的 Begin 返回带响应的记录；占用已被其他请求接管时返回 ErrLeaseLost
+	Complete(ctx context.Context, key, lease string, resp *Response) error
+	// Release 删除本次占用的处理中记录，允许客户端重试；占用已被接管时不做任何操作
+	Release(ctx context.Context, key, lease string) error
+	// Purge 删除 before 之前过期的记录，返回删除数量
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+var (
+	defaultStore atomic.Pointer[storeHolder]
+	fallback     = NewMemoryStore()
+)
+
+// storeHolder 包装接口值以便原子替换
+type storeHolder struct {
+	store Store
+}
+
+// SetStore 设置全局存储，由 idempotency 模块在启动时根据配置调用
+func SetStore(s Store) {
+	defaultStore.Store(&storeHolder{store: s})
+}
+
+// GetStore 获取全局存储，未设置时使用进程内存储
+func GetStore() Store {
+	if h := defaultStore.Load(); h != nil && h.store != nil {
+		return h.store
+	}
+	return fallback
+}
+
+// newLeaseToken 生成随机占用令牌
+func newLeaseToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
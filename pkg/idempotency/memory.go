@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore 进程内存储，重启后丢失，多实例部署时各实例不共享
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// NewMemoryStore 创建MemoryStore实例
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+// Begin 占用幂等键
+func (s *MemoryStore) Begin(ctx context.Context, key, fingerprint string, lock, ttl time.Duration) (*Record, string, error) {
+	lease, err := newLeaseToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if existing, ok := s.records[key]; ok && existing.ExpiresAt.After(now) &&
+		(existing.Completed() || existing.LockedUntil.After(now)) {
+		copied := *existing
+		return &copied, "", nil
+	}
+	s.records[key] = &Record{Key: key, Fingerprint: fingerprint, LeaseToken: lease, LockedUntil: now.Add(lock), ExpiresAt: now.Add(ttl)}
+	return nil, lease, nil
+}
+
+// Complete 保存响应
+func (s *MemoryStore) Complete(ctx context.Context, key, lease string, resp *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || record.LeaseToken != lease {
+		return ErrLeaseLost
+	}
+	record.Response = resp
+	return nil
+}
+
+// Release 删除本次占用的处理中记录
+func (s *MemoryStore) Release(ctx context.Context, key, lease string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[key]; ok && record.LeaseToken == lease && !record.Completed() {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// Purge 删除过期记录
+func (s *MemoryStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for key, record := range s.records {
+		if !record.ExpiresAt.After(before) {
+			delete(s.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,104 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestMemoryStore_Lease 占用超时被接管后，原请求不能保存响应或删除接管者的记录
+func TestMemoryStore_Lease(t *testing.T) {
+	ctx := context.Background()
+	resp := &Response{Status: http.StatusCreated, Body: []byte(`{"code":0}`)}
+
+	tests := []struct {
+		name         string
+		lock         time.Duration
+		wantTakeover bool
+		finish       func(s Store, first, second string) error
+		wantErr      error
+		wantFinal    string // 之后再次 Begin 的结果: acquired / processing / completed
+	}{
+		{
+			name:      "占用未超时时原请求保存响应",
+			lock:      time.Minute,
+			finish:    func(s Store, first, _ string) error { return s.Complete(ctx, "k", first, resp) },
+			wantFinal: "completed",
+		},
+		{
+			name:      "占用未超时时原请求释放后可重新占用",
+			lock:      time.Minute,
+			finish:    func(s Store, first, _ string) error { return s.Release(ctx, "k", first) },
+			wantFinal: "acquired",
+		},
+		{
+			name:         "被接管后原请求保存响应返回 ErrLeaseLost",
+			lock:         time.Millisecond,
+			wantTakeover: true,
+			finish:       func(s Store, first, _ string) error { return s.Complete(ctx, "k", first, resp) },
+			wantErr:      ErrLeaseLost,
+			wantFinal:    "processing",
+		},
+		{
+			name:         "被接管后原请求释放不删除接管者的记录",
+			lock:         time.Millisecond,
+			wantTakeover: true,
+			finish:       func(s Store, first, _ string) error { return s.Release(ctx, "k", first) },
+			wantFinal:    "processing",
+		},
+		{
+			name:         "接管者保存响应",
+			lock:         time.Millisecond,
+			wantTakeover: true,
+			finish:       func(s Store, _, second string) error { return s.Complete(ctx, "k", second, resp) },
+			wantFinal:    "completed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+
+			record, first, err := s.Begin(ctx, "k", "fp", tt.lock, time.Hour)
+			if err != nil || record != nil || first == "" {
+				t.Fatalf("首次占用失败: record=%v, lease=%q, err=%v", record, first, err)
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			record, second, err := s.Begin(ctx, "k", "fp", time.Minute, time.Hour)
+			if err != nil {
+				t.Fatalf("再次占用失败: %v", err)
+			}
+			if tt.wantTakeover {
+				if record != nil || second == "" || second == first {
+					t.Fatalf("期望接管占用: record=%v, lease=%q", record, second)
+				}
+			} else if record == nil || record.Completed() || second != "" {
+				t.Fatalf("期望返回处理中的记录: record=%v, lease=%q", record, second)
+			}
+
+			if err := tt.finish(s, first, second); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("错误 = %v, 期望 %v", err, tt.wantErr)
+			}
+
+			record, lease, err := s.Begin(ctx, "k", "fp", time.Minute, time.Hour)
+			if err != nil {
+				t.Fatalf("最终占用失败: %v", err)
+			}
+			var got string
+			switch {
+			case record == nil && lease != "":
+				got = "acquired"
+			case record != nil && record.Completed():
+				got = "completed"
+			case record != nil:
+				got = "processing"
+			}
+			if got != tt.wantFinal {
+				t.Errorf("最终状态 = %q, 期望 %q", got, tt.wantFinal)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package idgen 分布式ID生成
+// 提供 UUIDv7、ULID 和 Snowflake 三种按时间有序的ID，均为并发安全
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+var (
+	uuidMu     sync.Mutex
+	uuidLastMs int64
+	uuidSeq    uint16
+
+	ulidMu     sync.Mutex
+	ulidLastMs int64
+	ulidRand   [10]byte
+)
+
+// crockford ULID使用的Crockford Base32字母表
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewUUIDv7 生成UUIDv7字符串（RFC 9562）
+// 同一毫秒内使用12位计数器保证单调递增
+func NewUUIDv7() string {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic("idgen: 读取随机数失败: " + err.Error())
+	}
+
+	uuidMu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= uuidLastMs {
+		uuidSeq++
+		if uuidSeq > 0x0fff {
+			uuidSeq = 0
+			uuidLastMs++
+		}
+		ms = uuidLastMs
+	} else {
+		uuidSeq = binary.BigEndian.Uint16(b[6:8]) & 0x07ff
+		uuidLastMs = ms
+	}
+	seq := uuidSeq
+	uuidMu.Unlock()
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = 0x70 | byte(seq>>8)
+	b[7] = byte(seq)
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], b[10:])
+	return string(out[:])
+}
+
+// NewULID 生成ULID字符串（26位Crockford Base32）
+// 同一毫秒内随机部分递增，保证单调有序；随机部分溢出时进位到下一毫秒
+func NewULID() string {
+	ulidMu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= ulidLastMs && incrementRandom(&ulidRand) {
+		ms = ulidLastMs
+	} else {
+		if ms <= ulidLastMs {
+			ms = ulidLastMs + 1
+		}
+		if _, err := rand.Read(ulidRand[:]); err != nil {
+			ulidMu.Unlock()
+			panic("idgen: 读取随机数失败: " + err.Error())
+		}
+		ulidLastMs = ms
+	}
+	var b [16]byte
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	copy(b[6:], ulidRand[:])
+	ulidMu.Unlock()
+
+	return encodeCrockford(b)
+}
+
+// incrementRandom 随机部分加1，溢出时返回 false 且不修改随机部分
+func incrementRandom(r *[10]byte) bool {
+	for i := len(r) - 1; i >= 0; i-- {
+		if r[i] != 0xff {
+			r[i]++
+			for j := i + 1; j < len(r); j++ {
+				r[j] = 0
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCrockford 将128位数据编码为26位Crockford Base32
+func encodeCrockford(b [16]byte) string {
+	hi := binary.BigEndian.Uint64(b[0:8])
+	lo := binary.BigEndian.Uint64(b[8:16])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
@@ -0,0 +1,105 @@
+package idgen
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestMonotonic 连续生成的ID严格递增
+func TestMonotonic(t *testing.T) {
+	sf, err := NewSnowflake(1)
+	if err != nil {
+		t.Fatalf("创建Snowflake失败: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		next func() string
+	}{
+		{name: "UUIDv7", next: NewUUIDv7},
+		{name: "ULID", next: NewULID},
+		{name: "Snowflake", next: func() string { return strconv.FormatInt(sf.Next(), 36) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := tt.next()
+			for i := 0; i < 10000; i++ {
+				id := tt.next()
+				if !less(prev, id) {
+					t.Fatalf("第%d个ID未递增: %q -> %q", i, prev, id)
+				}
+				prev = id
+			}
+		})
+	}
+}
+
+// TestULID_RandomOverflow 同一毫秒内随机部分进位或溢出时仍保持递增
+func TestULID_RandomOverflow(t *testing.T) {
+	future := time.Now().Add(time.Hour).UnixMilli()
+
+	tests := []struct {
+		name     string
+		rand     [10]byte
+		wantMs   int64
+		wantRand [10]byte
+	}{
+		{
+			name:     "低位进位",
+			rand:     [10]byte{0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0xff},
+			wantMs:   future,
+			wantRand: [10]byte{0, 0, 0, 0, 0, 0, 0, 0, 0x02, 0x00},
+		},
+		{
+			name:   "随机部分溢出进位到下一毫秒",
+			rand:   [10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			wantMs: future + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ulidMu.Lock()
+			ulidLastMs = future
+			ulidRand = tt.rand
+			var b [16]byte
+			b[0] = byte(future >> 40)
+			b[1] = byte(future >> 32)
+			b[2] = byte(future >> 24)
+			b[3] = byte(future >> 16)
+			b[4] = byte(future >> 8)
+			b[5] = byte(future)
+			copy(b[6:], tt.rand[:])
+			prev := encodeCrockford(b)
+			ulidMu.Unlock()
+
+			id := NewULID()
+			if !less(prev, id) {
+				t.Fatalf("ID未递增: %q -> %q", prev, id)
+			}
+
+			ulidMu.Lock()
+			defer ulidMu.Unlock()
+			if ulidLastMs != tt.wantMs {
+				t.Errorf("时间戳 = %d, 期望 %d", ulidLastMs, tt.wantMs)
+			}
+			if tt.wantMs == future && ulidRand != tt.wantRand {
+				t.Errorf("随机部分 = %x, 期望 %x", ulidRand, tt.wantRand)
+			}
+		})
+	}
+
+	ulidMu.Lock()
+	ulidLastMs = 0
+	ulidMu.Unlock()
+}
+
+// less 按长度再按字典序比较ID
+func less(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,78 @@
+package idgen
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Snowflake ID布局: 1位符号 + 41位毫秒时间戳 + 10位节点ID + 12位序列号
+const (
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeMaxNode  = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSeq   = 1<<snowflakeSeqBits - 1
+)
+
+// snowflakeEpoch 自定义纪元 2024-01-01 00:00:00 UTC（毫秒）
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+var defaultSnowflake = &Snowflake{}
+
+// Snowflake Snowflake ID生成器
+type Snowflake struct {
+	mu     sync.Mutex
+	node   int64
+	lastMs int64
+	seq    int64
+}
+
+// NewSnowflake 创建指定节点ID的生成器，节点ID范围 0-1023
+func NewSnowflake(node int64) (*Snowflake, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, fmt.Errorf("snowflake节点ID必须在0-%d之间: %d", snowflakeMaxNode, node)
+	}
+	return &Snowflake{node: node}, nil
+}
+
+// SetSnowflakeNode 设置全局生成器的节点ID，多实例部署时每个实例必须不同
+func SetSnowflakeNode(node int64) error {
+	if node < 0 || node > snowflakeMaxNode {
+		return fmt.Errorf("snowflake节点ID必须在0-%d之间: %d", snowflakeMaxNode, node)
+	}
+	defaultSnowflake.mu.Lock()
+	defaultSnowflake.node = node
+	defaultSnowflake.mu.Unlock()
+	return nil
+}
+
+// NewSnowflakeID 使用全局生成器生成ID
+func NewSnowflakeID() int64 {
+	return defaultSnowflake.Next()
+}
+
+// Next 生成下一个ID，同一毫秒序列号耗尽时等待下一毫秒
+// 时钟回拨时沿用上次时间戳继续递增序列号
+func (s *Snowflake) Next() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := time.Now().UnixMilli()
+	if ms <= s.lastMs {
+		s.seq = (s.seq + 1) & snowflakeMaxSeq
+		if s.seq == 0 {
+			s.lastMs++
+			for time.Now().UnixMilli() < s.lastMs {
+				time.Sleep(100 * time.Microsecond)
+			}
+		}
+		ms = s.lastMs
+	} else {
+		s.seq = 0
+		s.lastMs = ms
+	}
+
+	return (ms-snowflakeEpoch)<<(snowflakeNodeBits+snowflakeSeqBits) |
+		s.node<<snowflakeSeqBits |
+		s.seq
+}
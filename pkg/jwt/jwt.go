@@ -0,0 +1,81 @@
+// Package jwt HS256 JWT的签发和校验，由 middleware.Auth 校验请求中的Token
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken Token格式、算法或签名错误
+	ErrInvalidToken = errors.New("Token无效")
+	// ErrExpiredToken Token已过期
+	ErrExpiredToken = errors.New("Token已过期")
+)
+
+// header 固定的Token头，只支持HS256
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims Token中的声明
+type Claims struct {
+	UserID    uint     `json:"user_id"`
+	Roles     []string `json:"roles,omitempty"` // 用户角色，middleware.RequireRole 据此校验权限
+	Issuer    string   `json:"iss,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// GenerateToken 签发Token，ttl 为有效期
+func GenerateToken(claims Claims, secret string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(ttl).Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + sign(unsigned, secret), nil
+}
+
+// ParseToken 校验算法、签名和有效期，返回Token中的声明
+func ParseToken(token, secret string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &h) != nil || h.Alg != "HS256" {
+		return nil, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(parts[0]+"."+parts[1], secret))) {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	raw, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.ExpiresAt == 0 || time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+// sign 计算HMAC-SHA256签名
+func sign(unsigned, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
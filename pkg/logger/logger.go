@@ -2,17 +2,23 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"os"
 	"sync"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"ruleback/internal/config"
+	"ruleback/pkg/reqctx"
 )
 
 var (
 	globalLogger *zap.Logger
 	globalSugar  *zap.SugaredLogger
+	globalLevel  = zap.NewAtomicLevel()
+	globalOutput = zapcore.AddSync(io.Discard)
 	loggerOnce   sync.Once
 	initErr      error
 )
@@ -20,7 +26,7 @@ var (
 // Init 初始化日志（使用sync.Once确保只初始化一次）
 func Init(cfg *config.LogConfig) error {
 	loggerOnce.Do(func() {
-		level := parseLevel(cfg.Level)
+		globalLevel.SetLevel(parseLevel(cfg.Level))
 
 		encoderConfig := zapcore.EncoderConfig{
 			TimeKey:        "time",
@@ -55,8 +61,11 @@ func Init(cfg *config.LogConfig) error {
 		} else {
 			writeSyncer = zapcore.AddSync(os.Stdout)
 		}
+		// 结构化日志和 Output 写入的文本共用同一输出，加锁避免并发写入时行交错
+		writeSyncer = zapcore.Lock(writeSyncer)
+		globalOutput = writeSyncer
 
-		core := zapcore.NewCore(encoder, writeSyncer, level)
+		core := zapcore.NewCore(encoder, writeSyncer, globalLevel)
 		globalLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 		globalSugar = globalLogger.Sugar()
 	})
@@ -79,6 +88,17 @@ func parseLevel(level string) zapcore.Level {
 	}
 }
 
+// Output 返回日志的输出目标，用于写入非结构化的文本行（如Apache格式的访问日志）
+// 写入的内容不经过级别过滤，未初始化时丢弃
+func Output() io.Writer {
+	return globalOutput
+}
+
+// SetLevel 运行时调整日志级别（用于配置热更新）
+func SetLevel(level string) {
+	globalLevel.SetLevel(parseLevel(level))
+}
+
 // Field 创建日志字段
 func Field(key string, value interface{}) zap.Field {
 	return zap.Any(key, value)
@@ -154,6 +174,53 @@ func Fatal(msg string, fields ...zap.Field) {
 	}
 }
 
+// DebugContext 调试日志，附带context中的请求ID和trace信息
+func DebugContext(ctx context.Context, msg string, fields ...zap.Field) {
+	if globalLogger != nil {
+		globalLogger.Debug(msg, append(fields, ContextFields(ctx)...)...)
+	}
+}
+
+// InfoContext 信息日志，附带context中的请求ID和trace信息
+func InfoContext(ctx context.Context, msg string, fields ...zap.Field) {
+	if globalLogger != nil {
+		globalLogger.Info(msg, append(fields, ContextFields(ctx)...)...)
+	}
+}
+
+// WarnContext 警告日志，附带context中的请求ID和trace信息
+func WarnContext(ctx context.Context, msg string, fields ...zap.Field) {
+	if globalLogger != nil {
+		globalLogger.Warn(msg, append(fields, ContextFields(ctx)...)...)
+	}
+}
+
+// ErrorContext 错误日志，附带context中的请求ID和trace信息
+func ErrorContext(ctx context.Context, msg string, fields ...zap.Field) {
+	if globalLogger != nil {
+		globalLogger.Error(msg, append(fields, ContextFields(ctx)...)...)
+	}
+}
+
+// ContextFields 从context中提取 request_id、trace_id、span_id 字段，不存在的字段不输出
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []zap.Field
+	if requestID := reqctx.GetRequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return fields
+}
+
 // Debugf 格式化调试日志
 func Debugf(template string, args ...interface{}) {
 	if globalSugar != nil {
@@ -0,0 +1,93 @@
+// Package metrics Prometheus 指标，由 /metrics 输出
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry 应用的指标注册表，不使用 prometheus 默认注册表，避免第三方库的指标混入
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP请求总数",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP请求处理耗时",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "正在处理的HTTP请求数",
+	})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "数据库操作耗时",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewBuildInfoCollector(),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		dbDuration,
+	)
+}
+
+// Handler 输出所有指标的HTTP处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveHTTP 记录一次HTTP请求，route 为路由模板（如 /api/v1/users/:id），不能使用原始路径
+func ObserveHTTP(method, route, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// HTTPInFlight 正在处理的HTTP请求数
+func HTTPInFlight() prometheus.Gauge {
+	return httpInFlight
+}
+
+// ObserveDB 记录一次数据库操作
+func ObserveDB(operation, table string, duration time.Duration) {
+	dbDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+// NewCounter 注册自定义计数器，同名且标签相同的计数器已注册时返回已有实例
+// 名称使用 模块_事件_total 格式，如 order_created_total；标签值必须是有限集合，不能使用ID等无限值
+func NewCounter(name, help string, labels ...string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)
+	if err := registry.Register(counter); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(*prometheus.CounterVec); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return counter
+}
+
+// Register 注册其他类型的指标，如连接池、队列长度等 Collector
+func Register(c prometheus.Collector) error {
+	return registry.Register(c)
+}
@@ -0,0 +1,66 @@
+// Package module 业务模块定义和生命周期管理
+package module
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"ruleback/pkg/database"
+)
+
+// Module 业务模块，一个功能的模型、路由、迁移和生命周期钩子集中在一处声明
+// 实现时嵌入 Base 只需覆盖用到的方法
+type Module interface {
+	// Name 模块名称，全局唯一，用于依赖声明和日志
+	Name() string
+	// Models 模块拥有的模型，启动时校验其表已由迁移创建
+	Models() []interface{}
+	// Routes 注册模块路由
+	Routes(r Routes)
+	// Migrations 模块的版本化迁移
+	Migrations() []database.Migration
+	// OnStart 迁移完成后、HTTP服务器启动前调用，按依赖顺序执行
+	OnStart(ctx context.Context) error
+	// OnStop HTTP服务器关闭后调用，按启动的相反顺序执行
+	OnStop(ctx context.Context) error
+	// HealthChecks 模块的健康检查，由 /health 调用
+	HealthChecks() []HealthCheck
+}
+
+// Dependent 声明依赖的模块，被依赖的模块先启动、后关闭
+type Dependent interface {
+	DependsOn() []string
+}
+
+// Routes 模块路由分组，均以 /api/v1 为前缀
+type Routes struct {
+	Public        *gin.RouterGroup // 公开路由
+	Authenticated *gin.RouterGroup // 需要认证的路由
+}
+
+// HealthCheck 健康检查项
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Base 模块方法的空实现，嵌入后按需覆盖
+type Base struct{}
+
+// Models 默认没有模型
+func (Base) Models() []interface{} { return nil }
+
+// Routes 默认不注册路由
+func (Base) Routes(Routes) {}
+
+// Migrations 默认没有迁移
+func (Base) Migrations() []database.Migration { return nil }
+
+// OnStart 默认不执行任何操作
+func (Base) OnStart(context.Context) error { return nil }
+
+// OnStop 默认不执行任何操作
+func (Base) OnStop(context.Context) error { return nil }
+
+// HealthChecks 默认没有健康检查
+func (Base) HealthChecks() []HealthCheck { return nil }
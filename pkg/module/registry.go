@@ -0,0 +1,181 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"ruleback/pkg/database"
+	"ruleback/pkg/logger"
+)
+
+// HealthStatus 健康检查结果
+type HealthStatus struct {
+	Module  string `json:"module"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Registry 已注册的模块，按依赖顺序排列
+type Registry struct {
+	modules []Module
+	started []Module
+}
+
+// NewRegistry 注册模块并按依赖关系排序
+// 无依赖关系的模块保持传入顺序；名称重复、依赖不存在或循环依赖时返回错误
+func NewRegistry(modules ...Module) (*Registry, error) {
+	byName := make(map[string]Module, len(modules))
+	for _, m := range modules {
+		name := m.Name()
+		if name == "" {
+			return nil, fmt.Errorf("模块 %T 缺少名称", m)
+		}
+		if _, ok := byName[name]; ok {
+			return nil, fmt.Errorf("模块名称重复: %s", name)
+		}
+		byName[name] = m
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(modules))
+	sorted := make([]Module, 0, len(modules))
+
+	var visit func(m Module, path []string) error
+	visit = func(m Module, path []string) error {
+		name := m.Name()
+		path = append(path, name)
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("模块存在循环依赖: %s", strings.Join(path, " -> "))
+		}
+
+		state[name] = visiting
+		for _, dep := range dependencies(m) {
+			depModule, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("模块 %s 依赖的模块 %s 未注册", name, dep)
+			}
+			if err := visit(depModule, path); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, m)
+		return nil
+	}
+
+	for _, m := range modules {
+		if err := visit(m, nil); err != nil {
+			return nil, err
+		}
+	}
+	return &Registry{modules: sorted}, nil
+}
+
+// dependencies 返回模块声明的依赖
+func dependencies(m Module) []string {
+	if d, ok := m.(Dependent); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+// Modules 返回按依赖顺序排列的模块
+func (r *Registry) Modules() []Module {
+	if r == nil {
+		return nil
+	}
+	return r.modules
+}
+
+// Migrations 返回所有模块的迁移
+func (r *Registry) Migrations() []database.Migration {
+	var migrations []database.Migration
+	for _, m := range r.Modules() {
+		migrations = append(migrations, m.Migrations()...)
+	}
+	return migrations
+}
+
+// CheckTables 确认所有模块模型对应的表已存在，用于发现遗漏的迁移
+func (r *Registry) CheckTables(db *gorm.DB) error {
+	for _, m := range r.Modules() {
+		for _, model := range m.Models() {
+			if !db.Migrator().HasTable(model) {
+				return fmt.Errorf("模块 %s 的模型 %T 对应的表不存在，请在模块的 Migrations 中添加迁移", m.Name(), model)
+			}
+		}
+	}
+	return nil
+}
+
+// RegisterRoutes 按依赖顺序注册所有模块的路由
+func (r *Registry) RegisterRoutes(routes Routes) {
+	for _, m := range r.Modules() {
+		m.Routes(routes)
+	}
+}
+
+// Start 按依赖顺序启动模块
+// 任一模块启动失败时，按相反顺序关闭已启动的模块并返回错误
+func (r *Registry) Start(ctx context.Context) error {
+	for _, m := range r.Modules() {
+		if err := m.OnStart(ctx); err != nil {
+			startErr := fmt.Errorf("模块 %s 启动失败: %w", m.Name(), err)
+			if stopErr := r.Stop(ctx); stopErr != nil {
+				return errors.Join(startErr, stopErr)
+			}
+			return startErr
+		}
+		r.started = append(r.started, m)
+		logger.Info("模块已启动", logger.String("module", m.Name()))
+	}
+	return nil
+}
+
+// Stop 按启动的相反顺序关闭已启动的模块
+// 单个模块关闭失败不影响其他模块，返回所有错误
+func (r *Registry) Stop(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		m := r.started[i]
+		if err := m.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("模块 %s 关闭失败: %w", m.Name(), err))
+			continue
+		}
+		logger.Info("模块已关闭", logger.String("module", m.Name()))
+	}
+	r.started = nil
+	return errors.Join(errs...)
+}
+
+// CheckHealth 执行所有模块的健康检查，返回结果和是否全部健康
+func (r *Registry) CheckHealth(ctx context.Context) ([]HealthStatus, bool) {
+	statuses := []HealthStatus{}
+	healthy := true
+	for _, m := range r.Modules() {
+		for _, check := range m.HealthChecks() {
+			status := HealthStatus{Module: m.Name(), Name: check.Name, Healthy: true}
+			if err := check.Check(ctx); err != nil {
+				status.Healthy = false
+				status.Error = err.Error()
+				healthy = false
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, healthy
+}
@@ -0,0 +1,70 @@
+// Package reqctx 请求上下文数据传递
+// 在HTTP层写入、在Service/Repository层读取，避免业务层依赖gin.Context
+package reqctx
+
+import "context"
+
+type contextKey int
+
+const (
+	userIDKey contextKey = iota
+	requestIDKey
+	rolesKey
+	principalKey
+)
+
+// WithUserID 将当前操作人ID写入context
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// GetUserID 获取context中的操作人ID
+func GetUserID(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	userID, ok := ctx.Value(userIDKey).(uint)
+	return userID, ok && userID != 0
+}
+
+// WithRequestID 将请求ID写入context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// GetRequestID 获取context中的请求ID
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithRoles 将当前操作人的角色写入context
+func WithRoles(ctx context.Context, roles []string) context.Context {
+	return context.WithValue(ctx, rolesKey, roles)
+}
+
+// GetRoles 获取context中的操作人角色
+func GetRoles(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	roles, _ := ctx.Value(rolesKey).([]string)
+	return roles
+}
+
+// WithPrincipal 将已认证的调用方身份写入context，如客户端证书中的服务名
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// GetPrincipal 获取context中的调用方身份
+func GetPrincipal(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	principal, _ := ctx.Value(principalKey).(string)
+	return principal
+}
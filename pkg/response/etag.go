@@ -0,0 +1,57 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetVersionETag 以记录版本号设置ETag响应头，客户端更新时通过 If-Match 回传
+func SetVersionETag(c *gin.Context, version uint) {
+	c.Header("ETag", fmt.Sprintf("\"%d\"", version))
+}
+
+// SetLastModified 设置Last-Modified响应头，middleware.ETag 据此处理 If-Modified-Since
+// 通常传入记录的 UpdatedAt，列表传入其中最大的 UpdatedAt
+func SetLastModified(c *gin.Context, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	c.Header("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// GetIfMatchVersion 解析 If-Match 请求头中的版本号
+// 未携带请求头时 ok 为 false；格式错误时返回 error
+func GetIfMatchVersion(c *gin.Context) (version uint, ok bool, err error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+
+	header = strings.TrimPrefix(header, "W/")
+	header = strings.Trim(header, "\"")
+	v, err := strconv.ParseUint(header, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("无效的If-Match: %w", err)
+	}
+	return uint(v), true, nil
+}
+
+// FailWithVersionConflict 返回乐观锁版本不匹配的错误响应，附带记录当前版本
+// 请求携带 If-Match 时为前置条件失败，返回412；版本来自请求体时返回409
+func FailWithVersionConflict(c *gin.Context, code int, message string, currentVersion uint) {
+	status := http.StatusConflict
+	if c.GetHeader("If-Match") != "" {
+		status = http.StatusPreconditionFailed
+	}
+	SetVersionETag(c, currentVersion)
+	write(c, status, Response{
+		Code:    code,
+		Message: message,
+		Data:    gin.H{"current_version": currentVersion},
+	})
+}
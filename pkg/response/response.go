@@ -7,6 +7,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// codeKey gin上下文中记录已写出的响应码的键名
+const codeKey = "response_code"
+
 // Response 统一响应结构体
 type Response struct {
 	Code    int         `json:"code"`
@@ -23,9 +26,26 @@ type PageData struct {
 	TotalPages int         `json:"total_pages"`
 }
 
+// write 写出统一格式的响应，并在gin上下文中记录响应码
+func write(c *gin.Context, status int, resp Response) {
+	c.Set(codeKey, resp.Code)
+	c.JSON(status, resp)
+}
+
+// GetCode 获取本次请求已写出的响应码，未通过本包写出响应时 ok 为 false
+// Fail 返回HTTP 200，幂等、缓存等中间件需要据此区分成功和失败
+func GetCode(c *gin.Context) (code int, ok bool) {
+	v, exists := c.Get(codeKey)
+	if !exists {
+		return 0, false
+	}
+	code, ok = v.(int)
+	return code, ok
+}
+
 // Success 返回成功响应（无数据）
 func Success(c *gin.Context) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    0,
 		Message: "success",
 	})
@@ -33,7 +53,7 @@ func Success(c *gin.Context) {
 
 // SuccessWithData 返回成功响应（带数据）
 func SuccessWithData(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    0,
 		Message: "success",
 		Data:    data,
@@ -42,7 +62,7 @@ func SuccessWithData(c *gin.Context, data interface{}) {
 
 // SuccessWithMessage 返回成功响应（自定义消息）
 func SuccessWithMessage(c *gin.Context, message string) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    0,
 		Message: message,
 	})
@@ -50,7 +70,7 @@ func SuccessWithMessage(c *gin.Context, message string) {
 
 // SuccessWithDataAndMessage 返回成功响应（带数据和自定义消息）
 func SuccessWithDataAndMessage(c *gin.Context, data interface{}, message string) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    0,
 		Message: message,
 		Data:    data,
@@ -64,7 +84,7 @@ func SuccessWithPage(c *gin.Context, list interface{}, total int64, page, pageSi
 		totalPages++
 	}
 
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    0,
 		Message: "success",
 		Data: PageData{
@@ -79,7 +99,7 @@ func SuccessWithPage(c *gin.Context, list interface{}, total int64, page, pageSi
 
 // Fail 返回失败响应
 func Fail(c *gin.Context, code int, message string) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    code,
 		Message: message,
 	})
@@ -87,7 +107,7 @@ func Fail(c *gin.Context, code int, message string) {
 
 // FailWithData 返回失败响应（带错误详情）
 func FailWithData(c *gin.Context, code int, message string, data interface{}) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Code:    code,
 		Message: message,
 		Data:    data,
@@ -96,7 +116,7 @@ func FailWithData(c *gin.Context, code int, message string, data interface{}) {
 
 // BadRequest 返回400错误响应
 func BadRequest(c *gin.Context, message string) {
-	c.JSON(http.StatusBadRequest, Response{
+	write(c, http.StatusBadRequest, Response{
 		Code:    http.StatusBadRequest,
 		Message: message,
 	})
@@ -104,7 +124,7 @@ func BadRequest(c *gin.Context, message string) {
 
 // Unauthorized 返回401错误响应
 func Unauthorized(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, Response{
+	write(c, http.StatusUnauthorized, Response{
 		Code:    http.StatusUnauthorized,
 		Message: message,
 	})
@@ -112,7 +132,7 @@ func Unauthorized(c *gin.Context, message string) {
 
 // Forbidden 返回403错误响应
 func Forbidden(c *gin.Context, message string) {
-	c.JSON(http.StatusForbidden, Response{
+	write(c, http.StatusForbidden, Response{
 		Code:    http.StatusForbidden,
 		Message: message,
 	})
@@ -120,16 +140,57 @@ func Forbidden(c *gin.Context, message string) {
 
 // NotFound 返回404错误响应
 func NotFound(c *gin.Context, message string) {
-	c.JSON(http.StatusNotFound, Response{
+	write(c, http.StatusNotFound, Response{
 		Code:    http.StatusNotFound,
 		Message: message,
 	})
 }
 
+// Conflict 返回409错误响应
+func Conflict(c *gin.Context, message string) {
+	write(c, http.StatusConflict, Response{
+		Code:    http.StatusConflict,
+		Message: message,
+	})
+}
+
+// UnprocessableEntity 返回422错误响应
+func UnprocessableEntity(c *gin.Context, message string) {
+	write(c, http.StatusUnprocessableEntity, Response{
+		Code:    http.StatusUnprocessableEntity,
+		Message: message,
+	})
+}
+
+// RequestEntityTooLarge 返回413错误响应
+func RequestEntityTooLarge(c *gin.Context, message string) {
+	write(c, http.StatusRequestEntityTooLarge, Response{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: message,
+	})
+}
+
+// TooManyRequests 返回429错误响应
+func TooManyRequests(c *gin.Context, message string) {
+	write(c, http.StatusTooManyRequests, Response{
+		Code:    http.StatusTooManyRequests,
+		Message: message,
+	})
+}
+
 // InternalServerError 返回500错误响应
 func InternalServerError(c *gin.Context, message string) {
-	c.JSON(http.StatusInternalServerError, Response{
+	write(c, http.StatusInternalServerError, Response{
 		Code:    http.StatusInternalServerError,
 		Message: message,
 	})
 }
+
+// InternalServerErrorWithData 返回500错误响应（带调试详情），只在调试模式下使用
+func InternalServerErrorWithData(c *gin.Context, message string, data interface{}) {
+	write(c, http.StatusInternalServerError, Response{
+		Code:    http.StatusInternalServerError,
+		Message: message,
+		Data:    data,
+	})
+}
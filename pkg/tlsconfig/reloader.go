@@ -0,0 +1,143 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"ruleback/pkg/logger"
+)
+
+// reloadDelay 文件变化后等待的时间，证书和私钥通常分两次写入
+const reloadDelay = 200 * time.Millisecond
+
+// CertReloader 证书加载器，证书或私钥文件变化时重新加载，新连接使用新证书
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+
+	mu      sync.Mutex
+	watcher *fsnotify.Watcher
+}
+
+// NewCertReloader 创建证书加载器并立即加载证书
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate 用于 tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload 重新加载证书，失败时继续使用原证书
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("加载TLS证书失败: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("解析TLS证书失败: %w", err)
+	}
+	cert.Leaf = leaf
+
+	r.cert.Store(&cert)
+	logger.Info("TLS证书已加载",
+		logger.String("file", r.certFile),
+		logger.String("subject", leaf.Subject.String()),
+		logger.String("not_after", leaf.NotAfter.Format(time.RFC3339)),
+	)
+	return nil
+}
+
+// Watch 监听证书和私钥所在目录，文件变化时重新加载
+// 监听目录而不是文件，以支持原子替换和 Kubernetes Secret 的符号链接切换
+// onError 接收重新加载失败的错误（可为nil）
+func (r *CertReloader) Watch(onError func(err error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.watcher != nil {
+		return nil
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]bool{
+		filepath.Dir(r.certFile): true,
+		filepath.Dir(r.keyFile):  true,
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("监听证书目录 %s 失败: %w", dir, err)
+		}
+	}
+	r.watcher = watcher
+
+	go r.loop(watcher, onError)
+	return nil
+}
+
+// loop 合并短时间内的多次文件变化后重新加载
+func (r *CertReloader) loop(watcher *fsnotify.Watcher, onError func(err error)) {
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				timer.Stop()
+				return
+			}
+			if r.isRelevant(event.Name) {
+				timer.Reset(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				timer.Stop()
+				return
+			}
+			onError(err)
+		case <-timer.C:
+			if err := r.Reload(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// isRelevant 判断变化的文件是否为证书、私钥或 Kubernetes Secret 的数据目录链接
+func (r *CertReloader) isRelevant(name string) bool {
+	base := filepath.Base(name)
+	return base == filepath.Base(r.certFile) || base == filepath.Base(r.keyFile) || base == "..data"
+}
+
+// Close 停止监听
+func (r *CertReloader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.watcher == nil {
+		return nil
+	}
+	err := r.watcher.Close()
+	r.watcher = nil
+	return err
+}
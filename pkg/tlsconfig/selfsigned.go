@@ -0,0 +1,84 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// GenerateSelfSigned 生成本地开发和测试用的自签名服务端证书，返回PEM格式的证书和私钥
+// hosts 可以是域名或IP，写入证书的SAN
+func GenerateSelfSigned(hosts []string, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	if len(hosts) == 0 {
+		return nil, nil, fmt.Errorf("至少需要一个主机名")
+	}
+
+	template := newTemplate(hosts[0], validFor, x509.ExtKeyUsageServerAuth)
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	return selfSign(template)
+}
+
+// GenerateClientCert 生成本地测试用的自签名客户端证书，CN 和 DNS SAN 均为 name
+// 证书本身可作为 server.tls.client_auth.ca_file 使用
+func GenerateClientCert(name string, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("客户端名称不能为空")
+	}
+
+	template := newTemplate(name, validFor, x509.ExtKeyUsageClientAuth)
+	template.DNSNames = []string{name}
+	return selfSign(template)
+}
+
+// newTemplate 创建自签名证书模板
+func newTemplate(commonName string, validFor time.Duration, usage x509.ExtKeyUsage) *x509.Certificate {
+	now := time.Now()
+	return &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"RuleBack Dev"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+}
+
+// selfSign 生成私钥并签发证书
+func selfSign(template *x509.Certificate) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成私钥失败: %w", err)
+	}
+
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成证书序列号失败: %w", err)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("生成证书失败: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("编码私钥失败: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
@@ -0,0 +1,97 @@
+// Package tlsconfig HTTPS配置：证书热加载、TLS版本和加密套件策略、HTTP重定向
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"ruleback/internal/config"
+)
+
+// 加密套件策略
+const (
+	PolicyIntermediate = "intermediate" // TLS1.2 仅ECDHE+AEAD套件，TLS1.3 使用Go默认套件
+	PolicyModern       = "modern"       // 仅TLS1.3
+)
+
+// versions 配置值与TLS版本的对应关系
+var versions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// intermediateCiphers TLS1.2 允许的加密套件，均支持前向保密和AEAD
+// TLS1.3 的套件由Go固定，不受此列表影响
+var intermediateCiphers = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// New 根据配置创建 tls.Config，证书由 reloader 提供以支持热加载
+func New(cfg *config.TLSConfig, reloader *CertReloader) (*tls.Config, error) {
+	minVersion, ok := versions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("不支持的TLS版本: %q", cfg.MinVersion)
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	switch cfg.CipherPolicy {
+	case PolicyModern:
+		tlsCfg.MinVersion = tls.VersionTLS13
+	case PolicyIntermediate, "":
+		tlsCfg.CipherSuites = intermediateCiphers
+	default:
+		return nil, fmt.Errorf("不支持的加密套件策略: %q", cfg.CipherPolicy)
+	}
+
+	if err := applyClientAuth(tlsCfg, &cfg.ClientAuth); err != nil {
+		return nil, err
+	}
+	return tlsCfg, nil
+}
+
+// applyClientAuth 配置客户端证书校验
+// optional 模式下未提供证书的连接照常建立，由 middleware.ClientCert 在路由上要求证书
+func applyClientAuth(tlsCfg *tls.Config, cfg *config.ClientAuthConfig) error {
+	switch cfg.Mode {
+	case "none", "":
+		return nil
+	case "optional":
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	case "required":
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return fmt.Errorf("不支持的客户端证书模式: %q", cfg.Mode)
+	}
+
+	pool, err := LoadCertPool(cfg.CAFile)
+	if err != nil {
+		return err
+	}
+	tlsCfg.ClientCAs = pool
+	return nil
+}
+
+// LoadCertPool 从PEM文件加载CA证书，文件可包含多个证书
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取CA证书失败: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA证书文件 %s 中没有有效的PEM证书", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// NewTransport 包装 http.RoundTripper，为每个外部请求创建client span并注入 traceparent 请求头
+// base 为nil时使用 http.DefaultTransport
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
+
+// NewHTTPClient 创建带链路追踪的HTTP客户端，调用外部服务时使用
+// 请求必须通过 http.NewRequestWithContext 传入请求context，才能与当前请求的trace关联
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: NewTransport(nil),
+		Timeout:   timeout,
+	}
+}
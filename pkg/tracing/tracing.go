@@ -0,0 +1,112 @@
+// Package tracing 链路追踪，基于 OpenTelemetry，使用 W3C Trace Context 传播
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"ruleback/internal/config"
+	"ruleback/pkg/buildinfo"
+)
+
+// instrumentationName 本项目创建的span所属的instrumentation名称
+const instrumentationName = "ruleback"
+
+var (
+	provider *sdktrace.TracerProvider
+	output   io.Closer
+	mu       sync.Mutex
+)
+
+// Init 初始化链路追踪
+// 未开启时只设置W3C传播器：不记录span，但上游的trace上下文仍会传递给下游调用
+func Init(cfg *config.TracingConfig, app *config.AppConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if !cfg.Enabled || provider != nil {
+		return nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return fmt.Errorf("创建链路导出器失败: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(app.Name),
+		semconv.ServiceVersion(buildinfo.Version),
+		semconv.DeploymentEnvironment(app.Env),
+	))
+	if err != nil {
+		return fmt.Errorf("创建链路资源失败: %w", err)
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.GetSampleRatio()))),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// newExporter 按配置创建导出器
+func newExporter(cfg *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	case "file":
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		output = file
+		return stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+}
+
+// Tracer 返回本项目的Tracer，用于在业务代码中创建自定义span
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Shutdown 导出剩余的span并关闭导出器，应用退出前调用
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if provider == nil {
+		return nil
+	}
+
+	err := provider.Shutdown(ctx)
+	if output != nil {
+		if closeErr := output.Close(); err == nil {
+			err = closeErr
+		}
+		output = nil
+	}
+	provider = nil
+	return err
+}
@@ -129,7 +129,7 @@ print_info "项目初始化完成!"
 echo ""
 echo "后续步骤:"
 echo "  1. 编辑 configs/config.yaml 配置数据库连接"
-echo "  2. 运行项目: go run cmd/server/main.go cmd/server/bootstrap.go"
+echo "  2. 运行项目: go run ./cmd/server"
 echo ""
 echo "配合 AI 使用:"
 echo "  1. 让 AI 阅读 CLAUDE.md 了解项目规范"